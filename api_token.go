@@ -0,0 +1,215 @@
+package golanggorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTokenRevoked      = errors.New("token revoked")
+	ErrInsufficientScope = errors.New("token does not have the required scope")
+)
+
+// TokenPrefix -> awalan token yang diberikan ke client, memudahkan mengenali token yang bocor
+const TokenPrefix = "ggt_"
+
+// TokenScopes -> daftar scope, disimpan di satu kolom dipisah spasi
+type TokenScopes []string
+
+func (s TokenScopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *TokenScopes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return fmt.Errorf("token scopes: cannot scan %T", value)
+	}
+	return nil
+}
+
+func (TokenScopes) GormDataType() string {
+	return "string"
+}
+
+// Has -> true jika scope ada, scope "*" berarti semua scope
+func (s TokenScopes) Has(scope string) bool {
+	for _, value := range s {
+		if value == scope || value == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken -> token login (session) atau token api milik user, yang disimpan hanya hash dari token
+type APIToken struct {
+	ID         int64       `gorm:"primaryKey;column:id;autoIncrement"`
+	UserId     int         `gorm:"column:user_id;index"`
+	Name       string      `gorm:"column:name;size:100"`
+	TokenHash  string      `gorm:"column:token_hash;size:64;uniqueIndex"`
+	Scopes     TokenScopes `gorm:"column:scopes;size:255"`
+	ExpiresAt  time.Time   `gorm:"column:expires_at;index"`
+	LastUsedAt *time.Time  `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time  `gorm:"column:revoked_at"`
+	CreatedAt  time.Time   `gorm:"column:created_at;autoCreateTime"`
+	User       *User       `gorm:"foreignKey:user_id;references:id"` //relasi belongs to
+}
+
+func (a *APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// TokenService -> issue, validate, rotate dan revoke APIToken
+type TokenService struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewTokenService -> token baru berlaku selama ttl
+func NewTokenService(db *gorm.DB, ttl time.Duration) *TokenService {
+	return &TokenService{db: db, ttl: ttl, now: time.Now}
+}
+
+func (s *TokenService) issue(tx *gorm.DB, userID int, name string, scopes TokenScopes) (string, *APIToken, error) {
+	secret, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := TokenPrefix + secret
+	record := &APIToken{
+		UserId:    userID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: s.now().Add(s.ttl),
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return "", nil, err
+	}
+	return token, record, nil
+}
+
+// Issue -> token baru untuk user, token hanya dikembalikan sekali dan tidak bisa dibaca lagi dari database
+func (s *TokenService) Issue(ctx context.Context, userID int, name string, scopes ...string) (string, *APIToken, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return "", nil, err
+	}
+	if count == 0 {
+		return "", nil, ErrUserNotFound
+	}
+	return s.issue(s.db.WithContext(ctx), userID, name, scopes)
+}
+
+// find -> APIToken dari token, dicek belum revoke dan belum expire
+func (s *TokenService) find(tx *gorm.DB, token string) (*APIToken, error) {
+	var record APIToken
+	err := tx.Take(&record, "token_hash = ?", hashToken(token)).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.RevokedAt != nil {
+		return nil, ErrTokenRevoked
+	}
+	if !s.now().Before(record.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+	return &record, nil
+}
+
+// Validate -> APIToken beserta User jika token valid dan punya semua scope yang diminta, last_used_at ikut diperbarui
+func (s *TokenService) Validate(ctx context.Context, token string, scopes ...string) (*APIToken, error) {
+	db := s.db.WithContext(ctx)
+	record, err := s.find(db.Preload("User"), token)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if !record.Scopes.Has(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInsufficientScope, scope)
+		}
+	}
+
+	now := s.now()
+	if err := db.Model(record).UpdateColumn("last_used_at", now).Error; err != nil {
+		return nil, err
+	}
+	record.LastUsedAt = &now
+	return record, nil
+}
+
+// Rotate -> token lama di revoke dan diganti token baru dengan user, nama dan scope yang sama
+func (s *TokenService) Rotate(ctx context.Context, token string) (string, *APIToken, error) {
+	var (
+		rotated string
+		record  *APIToken
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		old, err := s.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), token)
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&APIToken{}).Where("id = ? AND revoked_at IS NULL", old.ID).UpdateColumn("revoked_at", s.now())
+		if result.Error != nil {
+			return result.Error
+		}
+		//token sudah di rotate oleh request lain
+		if result.RowsAffected == 0 {
+			return ErrTokenRevoked
+		}
+		rotated, record, err = s.issue(tx, old.UserId, old.Name, old.Scopes)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return rotated, record, nil
+}
+
+// Revoke -> token tidak bisa dipakai lagi
+func (s *TokenService) Revoke(ctx context.Context, token string) error {
+	result := s.db.WithContext(ctx).Model(&APIToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hashToken(token)).
+		UpdateColumn("revoked_at", s.now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// RevokeUser -> revoke semua token milik user (misal setelah ganti password), mengembalikan jumlah token
+func (s *TokenService) RevokeUser(ctx context.Context, userID int) (int64, error) {
+	result := s.db.WithContext(ctx).Model(&APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", s.now())
+	return result.RowsAffected, result.Error
+}
+
+// PurgeExpired -> menghapus token yang sudah expire atau sudah di revoke, dijalankan berkala
+func (s *TokenService) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at IS NOT NULL", s.now()).
+		Delete(&APIToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,100 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueAndValidateToken(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	service := NewTokenService(db, time.Hour)
+
+	token, record, err := service.Issue(ctx, seed.Users["gojo"], "cli", "wallet:read", "todo:write")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(token, TokenPrefix))
+	assert.NotEqual(t, token, record.TokenHash)
+
+	validated, err := service.Validate(ctx, token, "wallet:read")
+	assert.Nil(t, err)
+	assert.Equal(t, seed.Users["gojo"], validated.User.ID)
+	assert.NotNil(t, validated.LastUsedAt)
+	assert.Equal(t, TokenScopes{"wallet:read", "todo:write"}, validated.Scopes)
+
+	_, err = service.Validate(ctx, token, "wallet:write")
+	assert.True(t, errors.Is(err, ErrInsufficientScope))
+
+	_, err = service.Validate(ctx, TokenPrefix+"salah")
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+
+	_, _, err = service.Issue(ctx, -1, "cli")
+	assert.True(t, errors.Is(err, ErrUserNotFound))
+
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = service.Validate(ctx, token)
+	assert.True(t, errors.Is(err, ErrTokenExpired))
+}
+
+func TestRotateAndRevokeToken(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	service := NewTokenService(db, time.Hour)
+
+	token, _, err := service.Issue(ctx, seed.Users["nanami"], "session", "*")
+	assert.Nil(t, err)
+
+	rotated, record, err := service.Rotate(ctx, token)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token, rotated)
+	assert.Equal(t, "session", record.Name)
+
+	_, err = service.Validate(ctx, token)
+	assert.True(t, errors.Is(err, ErrTokenRevoked))
+	_, _, err = service.Rotate(ctx, token)
+	assert.True(t, errors.Is(err, ErrTokenRevoked))
+
+	validated, err := service.Validate(ctx, rotated, "apa:saja")
+	assert.Nil(t, err)
+	assert.Equal(t, seed.Users["nanami"], validated.UserId)
+
+	assert.Nil(t, service.Revoke(ctx, rotated))
+	assert.True(t, errors.Is(service.Revoke(ctx, rotated), ErrInvalidToken))
+	_, err = service.Validate(ctx, rotated)
+	assert.True(t, errors.Is(err, ErrTokenRevoked))
+
+	_, _, err = service.Issue(ctx, seed.Users["nanami"], "cli")
+	assert.Nil(t, err)
+	_, _, err = service.Issue(ctx, seed.Users["nanami"], "web")
+	assert.Nil(t, err)
+	revoked, err := service.RevokeUser(ctx, seed.Users["nanami"])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), revoked)
+}
+
+func TestPurgeExpiredTokens(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	service := NewTokenService(db, time.Hour)
+
+	active, _, err := service.Issue(ctx, seed.Users["gojo"], "active")
+	assert.Nil(t, err)
+	revoked, _, err := service.Issue(ctx, seed.Users["gojo"], "revoked")
+	assert.Nil(t, err)
+	assert.Nil(t, service.Revoke(ctx, revoked))
+
+	short := NewTokenService(db, -time.Minute)
+	_, _, err = short.Issue(ctx, seed.Users["gojo"], "expired")
+	assert.Nil(t, err)
+
+	purged, err := service.PurgeExpired(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	_, err = service.Validate(ctx, active)
+	assert.Nil(t, err)
+}
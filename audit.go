@@ -0,0 +1,303 @@
+package golanggorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang-gorm/migrate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// action audit yang ditulis ke UserLog.Action
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// auditSkipTables -> tabel yang tidak dicatat (log itu sendiri dan data teknis)
+var auditSkipTables = map[string]bool{
+	"user_logs":          true,
+	"idempotency_keys":   true,
+	migrate.DefaultTable: true,
+}
+
+// auditRedactedColumns -> nilai kolom ini tidak pernah ditulis ke log
+var auditRedactedColumns = map[string]bool{
+	"password":   true,
+	"token_hash": true,
+}
+
+const auditRedacted = "[redacted]"
+
+type actorContext struct{}
+
+// WithActor -> user yang melakukan perubahan, dicatat di UserLog.UserId oleh plugin Audit
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorContext{}, userID)
+}
+
+// ActorFrom -> user yang dipasang oleh WithActor
+func ActorFrom(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(actorContext{}).(int)
+	return userID, ok
+}
+
+// AuditChange -> nilai kolom sebelum dan sesudah perubahan
+type AuditChange struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// Audit -> plugin gorm yang mencatat create/update/delete semua model ke UserLog, aktif jika Config.Audit
+type Audit struct{}
+
+func (Audit) Name() string {
+	return "audit"
+}
+
+func (Audit) Initialize(db *gorm.DB) error {
+	callbacks := []error{
+		db.Callback().Create().After("gorm:create").Register("audit:create", auditCreate),
+		db.Callback().Update().Before("gorm:update").Register("audit:before_update", auditSnapshot),
+		db.Callback().Update().After("gorm:update").Register("audit:update", auditUpdate),
+		db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", auditSnapshot),
+		db.Callback().Delete().After("gorm:delete").Register("audit:delete", auditDelete),
+	}
+	for _, err := range callbacks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const auditSnapshotKey = "audit:snapshot"
+
+func audited(db *gorm.DB) bool {
+	stmt := db.Statement
+	return db.Error == nil && !db.DryRun && stmt.Schema != nil && len(stmt.Schema.PrimaryFields) > 0 && !auditSkipTables[stmt.Table]
+}
+
+// auditSession -> query tambahan di koneksi/transaction yang sama dengan statement yang sedang berjalan
+func auditSession(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: db.Statement.Context})
+}
+
+func auditValue(column string, value interface{}) interface{} {
+	if auditRedactedColumns[column] {
+		return auditRedacted
+	}
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return value
+}
+
+// rowOf -> kolom -> nilai dari satu struct model
+func rowOf(ctx context.Context, s *schema.Schema, rv reflect.Value) map[string]interface{} {
+	row := map[string]interface{}{}
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, rv)
+		if valuer, ok := value.(driver.Valuer); ok {
+			value, _ = valuer.Value()
+		}
+		row[field.DBName] = auditValue(field.DBName, value)
+	}
+	return row
+}
+
+func primaryKeyOf(s *schema.Schema, row map[string]interface{}) string {
+	parts := make([]string, 0, len(s.PrimaryFields))
+	for _, field := range s.PrimaryFields {
+		parts = append(parts, fmt.Sprint(row[field.DBName]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func auditCreate(db *gorm.DB) {
+	if !audited(db) {
+		return
+	}
+	stmt := db.Statement
+	var logs []UserLog
+	appendRow := func(rv reflect.Value) {
+		row := rowOf(stmt.Context, stmt.Schema, rv)
+		changes := map[string]AuditChange{}
+		for column, value := range row {
+			changes[column] = AuditChange{New: value}
+		}
+		logs = append(logs, newAuditLog(stmt, AuditCreate, primaryKeyOf(stmt.Schema, row), changes))
+	}
+
+	switch rv := stmt.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			appendRow(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		appendRow(rv)
+	}
+	writeAuditLogs(db, logs)
+}
+
+// auditSnapshot -> membaca baris yang akan diubah/dihapus sebelum statement dijalankan
+func auditSnapshot(db *gorm.DB) {
+	if !audited(db) {
+		return
+	}
+	rows, err := selectAffected(db)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditSnapshotKey, rows)
+}
+
+// selectAffected -> baris yang cocok dengan primary key model atau WHERE dari statement
+func selectAffected(db *gorm.DB) ([]map[string]interface{}, error) {
+	stmt := db.Statement
+	query := auditSession(db).Table(stmt.Table)
+
+	var conditions []clause.Expression
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		for _, field := range stmt.Schema.PrimaryFields {
+			if value, isZero := field.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+				conditions = append(conditions, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+			}
+		}
+	}
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = append(conditions, where.Exprs...)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	var rows []map[string]interface{}
+	err := query.Clauses(clause.Where{Exprs: conditions}).Find(&rows).Error
+	return rows, err
+}
+
+func snapshotOf(db *gorm.DB) []map[string]interface{} {
+	rows, _ := db.InstanceGet(auditSnapshotKey)
+	snapshot, _ := rows.([]map[string]interface{})
+	return snapshot
+}
+
+func auditUpdate(db *gorm.DB) {
+	if !audited(db) || db.RowsAffected == 0 {
+		return
+	}
+	stmt := db.Statement
+	var logs []UserLog
+	for _, old := range snapshotOf(db) {
+		var current map[string]interface{}
+		query := auditSession(db).Table(stmt.Table)
+		for _, field := range stmt.Schema.PrimaryFields {
+			query = query.Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: old[field.DBName]})
+		}
+		if err := query.Take(&current).Error; err != nil {
+			continue
+		}
+
+		changes := map[string]AuditChange{}
+		for column, value := range current {
+			//dibandingkan sebelum redact, perubahan password tetap tercatat tanpa nilainya
+			if fmt.Sprint(auditValue("", old[column])) != fmt.Sprint(auditValue("", value)) {
+				changes[column] = AuditChange{Old: auditValue(column, old[column]), New: auditValue(column, value)}
+			}
+		}
+		if len(changes) > 0 {
+			logs = append(logs, newAuditLog(stmt, AuditUpdate, primaryKeyOf(stmt.Schema, old), changes))
+		}
+	}
+	writeAuditLogs(db, logs)
+}
+
+func auditDelete(db *gorm.DB) {
+	if !audited(db) || db.RowsAffected == 0 {
+		return
+	}
+	stmt := db.Statement
+	var logs []UserLog
+	for _, old := range snapshotOf(db) {
+		changes := map[string]AuditChange{}
+		for column, value := range old {
+			changes[column] = AuditChange{Old: auditValue(column, value)}
+		}
+		logs = append(logs, newAuditLog(stmt, AuditDelete, primaryKeyOf(stmt.Schema, old), changes))
+	}
+	writeAuditLogs(db, logs)
+}
+
+func newAuditLog(stmt *gorm.Statement, action, recordID string, changes map[string]AuditChange) UserLog {
+	log := UserLog{Action: action, Entity: stmt.Table, RecordId: recordID}
+	if actor, ok := ActorFrom(stmt.Context); ok {
+		log.UserId = &actor
+	}
+	data, _ := json.Marshal(changes)
+	log.Changes = string(data)
+	return log
+}
+
+func writeAuditLogs(db *gorm.DB, logs []UserLog) {
+	if len(logs) == 0 {
+		return
+	}
+	db.AddError(auditSession(db).Create(&logs).Error)
+}
+
+// Diff -> isi kolom changes dari log audit
+func (l *UserLog) Diff() (map[string]AuditChange, error) {
+	changes := map[string]AuditChange{}
+	if l.Changes == "" {
+		return changes, nil
+	}
+	err := json.Unmarshal([]byte(l.Changes), &changes)
+	return changes, err
+}
+
+// History -> semua log audit satu baris (table dan primary key), urut dari yang paling lama
+func History(ctx context.Context, db *gorm.DB, table string, recordID interface{}) ([]UserLog, error) {
+	var logs []UserLog
+	err := db.WithContext(ctx).
+		Where("entity = ? AND record_id = ?", table, fmt.Sprint(recordID)).
+		Order("id").Find(&logs).Error
+	return logs, err
+}
+
+// HistoryOf -> History untuk model yang primary key-nya sudah terisi, contoh HistoryOf(ctx, db, &wallet)
+func HistoryOf(ctx context.Context, db *gorm.DB, model interface{}) ([]UserLog, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(model))
+	return History(ctx, db, stmt.Schema.Table, primaryKeyOf(stmt.Schema, rowOf(ctx, stmt.Schema, rv)))
+}
+
+// ActorHistory -> semua perubahan yang dilakukan oleh satu user
+func ActorHistory(ctx context.Context, db *gorm.DB, userID int) ([]UserLog, error) {
+	var logs []UserLog
+	err := db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,117 @@
+package golanggorm
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditCreateUpdateDelete(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := WithActor(context.Background(), seed.Users["gojo"])
+
+	todo := Todo{UserId: seed.Users["nanami"], Title: "Belajar Audit"}
+	assert.Nil(t, db.WithContext(ctx).Create(&todo).Error)
+	assert.Nil(t, db.WithContext(ctx).Model(&todo).Update("title", "Belajar Audit Trail").Error)
+	assert.Nil(t, db.WithContext(ctx).Delete(&todo).Error)
+
+	logs, err := HistoryOf(ctx, db, &todo)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(logs))
+	for i, action := range []string{AuditCreate, AuditUpdate, AuditDelete} {
+		assert.Equal(t, action, logs[i].Action)
+		assert.Equal(t, "todos", logs[i].Entity)
+		assert.Equal(t, strconv.Itoa(int(todo.ID)), logs[i].RecordId)
+		assert.Equal(t, seed.Users["gojo"], *logs[i].UserId)
+	}
+
+	created, err := logs[0].Diff()
+	assert.Nil(t, err)
+	assert.Equal(t, "Belajar Audit", created["title"].New)
+
+	updated, err := logs[1].Diff()
+	assert.Nil(t, err)
+	assert.Equal(t, "Belajar Audit", updated["title"].Old)
+	assert.Equal(t, "Belajar Audit Trail", updated["title"].New)
+	assert.NotContains(t, updated, "description") //kolom yang tidak berubah tidak dicatat
+
+	deleted, err := logs[2].Diff()
+	assert.Nil(t, err)
+	assert.Equal(t, "Belajar Audit Trail", deleted["title"].Old)
+}
+
+func TestAuditBatchUpdate(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+
+	result := db.WithContext(ctx).Model(&Wallet{}).
+		Where("id IN ?", []int{seed.Wallets["gojo"], seed.Wallets["laksa"]}).
+		Update("balance", 0)
+	assert.Nil(t, result.Error)
+	assert.Equal(t, int64(2), result.RowsAffected)
+
+	for _, wallet := range []string{"gojo", "laksa"} {
+		logs, err := History(ctx, db, "wallets", seed.Wallets[wallet])
+		assert.Nil(t, err)
+		last := logs[len(logs)-1]
+		assert.Equal(t, AuditUpdate, last.Action)
+		assert.Nil(t, last.UserId) //tanpa WithActor, disimpan NULL
+
+		changes, err := last.Diff()
+		assert.Nil(t, err)
+		assert.Equal(t, float64(0), changes["balance"].New)
+	}
+
+	var system int64
+	assert.Nil(t, db.Model(&UserLog{}).Where("entity = ? AND action = ? AND user_id IS NULL", "wallets", AuditUpdate).Count(&system).Error)
+	assert.Equal(t, int64(2), system)
+}
+
+func TestAuditRedactsPassword(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := WithActor(context.Background(), seed.Users["toji"])
+
+	user := User{ID: seed.Users["toji"]}
+	assert.Nil(t, db.WithContext(ctx).Model(&user).Update("password", "rahasia baru").Error)
+
+	logs, err := History(ctx, db, "users", user.ID)
+	assert.Nil(t, err)
+	last := logs[len(logs)-1]
+	assert.NotContains(t, last.Changes, "rahasia")
+
+	changes, err := last.Diff()
+	assert.Nil(t, err)
+	assert.Equal(t, auditRedacted, changes["password"].New)
+}
+
+func TestActorHistory(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := WithActor(context.Background(), seed.Users["megumi"])
+
+	product := Product{Name: "Audit", Price: 1000}
+	assert.Nil(t, db.WithContext(ctx).Create(&product).Error)
+	assert.Nil(t, db.WithContext(ctx).Model(&Address{}).Where("id = ?", seed.Addresses["jalan_a"]).Update("address", "Jalan C").Error)
+	assert.Nil(t, db.Create(&Todo{UserId: seed.Users["megumi"], Title: "tanpa actor"}).Error)
+
+	logs, err := ActorHistory(ctx, db, seed.Users["megumi"])
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, "products", logs[0].Entity)
+	assert.Equal(t, strconv.Itoa(product.ID), logs[0].RecordId)
+	assert.Equal(t, "addresses", logs[1].Entity)
+}
+
+func TestAuditSkipsUserLogs(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	var before int64
+	assert.Nil(t, db.Model(&UserLog{}).Count(&before).Error)
+	gojo := seed.Users["gojo"]
+	assert.Nil(t, db.Create(&UserLog{UserId: &gojo, Action: "login"}).Error)
+
+	var after int64
+	assert.Nil(t, db.Model(&UserLog{}).Count(&after).Error)
+	assert.Equal(t, before+1, after)
+}
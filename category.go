@@ -0,0 +1,246 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryCycle    = errors.New("category cannot be moved under itself or its descendants")
+)
+
+// Category -> kategori product berbentuk tree, ParentID nil berarti root
+type Category struct {
+	ID        int        `gorm:"primary_key;column:id;autoIncrement"`
+	ParentID  *int       `gorm:"column:parent_id;index:idx_categories_parent_id"`
+	Name      string     `gorm:"column:name;size:100"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Parent    *Category  `gorm:"foreignKey:parent_id;references:id"` //relasi belongs to ke dirinya sendiri
+	Children  []Category `gorm:"foreignKey:parent_id;references:id"` //relasi one to many ke dirinya sendiri
+	Products  []Product  `gorm:"many2many:product_categories;foreignKey:id;joinForeignKey:category_id;references:id;joinReferences:product_id"`
+}
+
+func (c *Category) TableName() string {
+	return "categories"
+}
+
+// CategoryService -> tree kategori dan product di dalamnya
+type CategoryService struct {
+	db *gorm.DB
+}
+
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	return &CategoryService{db: db}
+}
+
+// categorySubtree -> id kategori beserta semua turunannya (recursive CTE, MySQL 8+, SQLite dan Postgres)
+func categorySubtree(tx *gorm.DB, id int) ([]int, error) {
+	var ids []int
+	err := tx.Raw(`WITH RECURSIVE subtree (id) AS (
+		SELECT id FROM categories WHERE id = ?
+		UNION ALL
+		SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+	) SELECT id FROM subtree`, id).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrCategoryNotFound, id)
+	}
+	return ids, nil
+}
+
+func lockCategory(tx *gorm.DB, id int) (*Category, error) {
+	var category Category
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&category, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrCategoryNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Create -> kategori baru di bawah parentID, nil untuk root
+func (s *CategoryService) Create(ctx context.Context, name string, parentID *int) (*Category, error) {
+	category := &Category{Name: strings.TrimSpace(name), ParentID: parentID}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if parentID != nil {
+			if _, err := lockCategory(tx, *parentID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(category).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Move -> memindahkan kategori (beserta semua turunannya) ke bawah parentID, nil untuk menjadi root
+func (s *CategoryService) Move(ctx context.Context, id int, parentID *int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		category, err := lockCategory(tx, id)
+		if err != nil {
+			return err
+		}
+		if parentID != nil {
+			if _, err := lockCategory(tx, *parentID); err != nil {
+				return err
+			}
+			subtree, err := categorySubtree(tx, id)
+			if err != nil {
+				return err
+			}
+			for _, descendant := range subtree {
+				if descendant == *parentID {
+					return fmt.Errorf("%w: %d under %d", ErrCategoryCycle, id, *parentID)
+				}
+			}
+		}
+		//turunan tetap menunjuk ke kategori ini, jadi ikut pindah tanpa diubah
+		return tx.Model(category).Update("parent_id", parentID).Error
+	})
+}
+
+// Delete -> menghapus kategori, anak-anaknya naik ke parent kategori yang dihapus
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		category, err := lockCategory(tx, id)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Category{}).Where("parent_id = ?", id).Update("parent_id", category.ParentID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(category).Association("Products").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
+
+// Subtree -> kategori beserta semua turunannya, Children terisi sampai ke daun
+func (s *CategoryService) Subtree(ctx context.Context, id int) (*Category, error) {
+	db := s.db.WithContext(ctx)
+	ids, err := categorySubtree(db, id)
+	if err != nil {
+		return nil, err
+	}
+	var categories []Category
+	if err := db.Where("id IN ?", ids).Order("name").Order("id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	//tree disusun di memory, satu query untuk semua level
+	children := map[int][]*Category{}
+	var root *Category
+	for i := range categories {
+		category := &categories[i]
+		if category.ID == id {
+			root = category
+		} else if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+	var build func(category *Category)
+	build = func(category *Category) {
+		for _, child := range children[category.ID] {
+			build(child)
+			category.Children = append(category.Children, *child)
+		}
+	}
+	build(root)
+	return root, nil
+}
+
+// Ancestors -> jalur dari root sampai parent kategori (breadcrumb), root lebih dulu
+func (s *CategoryService) Ancestors(ctx context.Context, id int) ([]Category, error) {
+	db := s.db.WithContext(ctx)
+	var ids []int
+	err := db.Raw(`WITH RECURSIVE ancestors (id, parent_id, depth) AS (
+		SELECT id, parent_id, 0 FROM categories WHERE id = ?
+		UNION ALL
+		SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+	) SELECT id FROM ancestors WHERE depth > 0 ORDER BY depth DESC`, id).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []Category
+	if err := db.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	ancestors := make([]Category, 0, len(ids))
+	for _, ancestorID := range ids {
+		ancestors = append(ancestors, byID[ancestorID])
+	}
+	return ancestors, nil
+}
+
+// SetCategories -> mengganti semua kategori product
+func (s *CategoryService) SetCategories(ctx context.Context, productID int, categoryIDs ...int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		product := &Product{ID: productID}
+		var count int64
+		if err := tx.Model(&Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: %d", ErrProductNotFound, productID)
+		}
+
+		var categories []Category
+		if len(categoryIDs) > 0 {
+			if err := tx.Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+				return err
+			}
+		}
+		if len(categories) != len(uniqueInts(categoryIDs)) {
+			return ErrCategoryNotFound
+		}
+		return tx.Model(product).Omit("Categories.*").Association("Categories").Replace(categories)
+	})
+}
+
+func uniqueInts(values []int) map[int]bool {
+	unique := make(map[int]bool, len(values))
+	for _, value := range values {
+		unique[value] = true
+	}
+	return unique
+}
+
+// ListProducts -> product di kategori dan semua turunannya tanpa duplikat, urut nama, beserta jumlah total
+func (s *CategoryService) ListProducts(ctx context.Context, categoryID int, page Page) ([]Product, int64, error) {
+	db := s.db.WithContext(ctx)
+	ids, err := categorySubtree(db, categoryID)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := db.Model(&Product{}).Where("id IN (?)",
+		db.Table("product_categories").Select("product_id").Where("category_id IN ?", ids))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var products []Product
+	err = query.Order("name").Order("id").Limit(page.limit()).Offset(page.offset()).Find(&products).Error
+	return products, total, err
+}
@@ -0,0 +1,134 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createCategoryTree -> Elektronik > (Komputer > Laptop, Audio)
+func createCategoryTree(t *testing.T, service *CategoryService) map[string]*Category {
+	ctx := context.Background()
+	tree := map[string]*Category{}
+	create := func(name string, parent string) {
+		var parentID *int
+		if parent != "" {
+			parentID = &tree[parent].ID
+		}
+		category, err := service.Create(ctx, name, parentID)
+		assert.Nil(t, err)
+		tree[name] = category
+	}
+	create("Elektronik", "")
+	create("Komputer", "Elektronik")
+	create("Laptop", "Komputer")
+	create("Audio", "Elektronik")
+	return tree
+}
+
+func TestCategoryListProducts(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewCategoryService(db)
+	ctx := context.Background()
+	tree := createCategoryTree(t, service)
+
+	assert.Nil(t, service.SetCategories(ctx, seed.Products["product_1"], tree["Laptop"].ID))
+	assert.Nil(t, service.SetCategories(ctx, seed.Products["product_2"], tree["Komputer"].ID, tree["Laptop"].ID))
+	assert.Nil(t, service.SetCategories(ctx, seed.Products["product_3"], tree["Audio"].ID))
+
+	//product_2 ada di dua kategori dalam subtree tapi hanya muncul sekali
+	products, total, err := service.ListProducts(ctx, tree["Elektronik"].ID, Page{Size: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, 2, len(products))
+	assert.Equal(t, "Contoh Product 1", products[0].Name)
+
+	products, _, err = service.ListProducts(ctx, tree["Elektronik"].ID, Page{Number: 2, Size: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(products))
+
+	products, total, err = service.ListProducts(ctx, tree["Komputer"].ID, Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, 2, len(products))
+
+	_, _, err = service.ListProducts(ctx, 999, Page{})
+	assert.True(t, errors.Is(err, ErrCategoryNotFound))
+	assert.True(t, errors.Is(service.SetCategories(ctx, seed.Products["product_1"], 999), ErrCategoryNotFound))
+	assert.True(t, errors.Is(service.SetCategories(ctx, 999, tree["Audio"].ID), ErrProductNotFound))
+
+	//mengganti kategori menghapus relasi lama
+	assert.Nil(t, service.SetCategories(ctx, seed.Products["product_2"]))
+	_, total, err = service.ListProducts(ctx, tree["Komputer"].ID, Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestCategoryMove(t *testing.T) {
+	db, _ := setupTestDB(t)
+	service := NewCategoryService(db)
+	ctx := context.Background()
+	tree := createCategoryTree(t, service)
+
+	//tidak boleh pindah ke dirinya sendiri atau ke turunannya
+	err := service.Move(ctx, tree["Elektronik"].ID, &tree["Laptop"].ID)
+	assert.True(t, errors.Is(err, ErrCategoryCycle))
+	err = service.Move(ctx, tree["Komputer"].ID, &tree["Komputer"].ID)
+	assert.True(t, errors.Is(err, ErrCategoryCycle))
+	err = service.Move(ctx, tree["Komputer"].ID, new(int))
+	assert.True(t, errors.Is(err, ErrCategoryNotFound))
+
+	//Komputer pindah ke bawah Audio, Laptop ikut pindah
+	assert.Nil(t, service.Move(ctx, tree["Komputer"].ID, &tree["Audio"].ID))
+	ancestors, err := service.Ancestors(ctx, tree["Laptop"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Elektronik", "Audio", "Komputer"}, categoryNames(ancestors))
+
+	//menjadi root
+	assert.Nil(t, service.Move(ctx, tree["Komputer"].ID, nil))
+	ancestors, err = service.Ancestors(ctx, tree["Laptop"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Komputer"}, categoryNames(ancestors))
+
+	root, err := service.Subtree(ctx, tree["Elektronik"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Audio"}, categoryNames(root.Children))
+	assert.Equal(t, 0, len(root.Children[0].Children))
+}
+
+func TestCategoryDeleteReparentsChildren(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewCategoryService(db)
+	ctx := context.Background()
+	tree := createCategoryTree(t, service)
+	assert.Nil(t, service.SetCategories(ctx, seed.Products["product_1"], tree["Komputer"].ID))
+
+	assert.Nil(t, service.Delete(ctx, tree["Komputer"].ID))
+	assert.True(t, errors.Is(service.Delete(ctx, tree["Komputer"].ID), ErrCategoryNotFound))
+
+	//Laptop naik ke Elektronik, product tidak ikut terhapus
+	root, err := service.Subtree(ctx, tree["Elektronik"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Audio", "Laptop"}, categoryNames(root.Children))
+
+	var links int64
+	assert.Nil(t, db.Table("product_categories").Where("category_id = ?", tree["Komputer"].ID).Count(&links).Error)
+	assert.Equal(t, int64(0), links)
+	assert.Equal(t, 5, productStock(t, db, seed.Products["product_1"]))
+
+	//root yang dihapus membuat anak-anaknya menjadi root
+	assert.Nil(t, service.Delete(ctx, tree["Elektronik"].ID))
+	ancestors, err := service.Ancestors(ctx, tree["Laptop"].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ancestors))
+}
+
+func categoryNames(categories []Category) []string {
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = category.Name
+	}
+	return names
+}
@@ -0,0 +1,337 @@
+package golanggorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrInvalidConfig -> semua error validasi config membungkus error ini
+var ErrInvalidConfig = errors.New("invalid database config")
+
+// ConfigError menjelaskan field config mana yang tidak valid
+type ConfigError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidConfig, e.Field, e.Reason)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// ConnectError -> error saat membuka koneksi (open, pool, ping)
+type ConnectError struct {
+	Op  string
+	Err error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("connect database (%s): %v", e.Op, e.Err)
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// Duration -> time.Duration yang bisa dibaca dari "30m" di file json/yaml
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		*d = Duration(time.Duration(v))
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration %s", string(data))
+	}
+	return nil
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if n, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+		*d = Duration(time.Duration(n))
+		return nil
+	}
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// dialect database yang didukung oleh Connect
+const (
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+)
+
+// Config -> pengaturan koneksi database, bisa diisi dari env atau file json/yaml
+type Config struct {
+	Dialect         string   `json:"dialect" yaml:"dialect"` // mysql (default), sqlite, postgres
+	DSN             string   `json:"dsn" yaml:"dsn"`
+	MaxOpenConns    int      `json:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time" yaml:"conn_max_idle_time"`
+	LogLevel        string   `json:"log_level" yaml:"log_level"` // silent, error, warn, info
+	Audit           bool     `json:"audit" yaml:"audit"`         // catat create/update/delete ke user_logs
+}
+
+// DefaultConfig -> nilai default yang sama dengan OpenConnection sebelumnya
+func DefaultConfig() Config {
+	return Config{
+		Dialect:         DialectMySQL,
+		DSN:             "root:@tcp(localhost:3306)/golang_gorm?charset=utf8mb4&parseTime=True&loc=Local",
+		MaxOpenConns:    100,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: Duration(30 * time.Minute),
+		ConnMaxIdleTime: Duration(5 * time.Minute),
+		LogLevel:        "info",
+	}
+}
+
+// LoadConfigFromEnv -> DefaultConfig yang ditimpa oleh env DB_*
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if err := cfg.applyEnv(os.Getenv); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// LoadConfigFile -> membaca config dari file .json, .yaml atau .yml (field yang kosong memakai default)
+func LoadConfigFile(path string) (Config, error) {
+	cfg := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		return Config{}, &ConfigError{Field: "file", Reason: "must be .json, .yaml or .yml"}
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) applyEnv(getenv func(string) string) error {
+	if v := getenv("DB_DIALECT"); v != "" {
+		c.Dialect = v
+	}
+	if v := getenv("DB_DSN"); v != "" {
+		c.DSN = v
+	}
+	if v := getenv("DB_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := getenv("DB_AUDIT"); v != "" {
+		audit, err := strconv.ParseBool(v)
+		if err != nil {
+			return &ConfigError{Field: "DB_AUDIT", Reason: "must be a boolean"}
+		}
+		c.Audit = audit
+	}
+
+	ints := []struct {
+		key string
+		dst *int
+	}{
+		{"DB_MAX_OPEN_CONNS", &c.MaxOpenConns},
+		{"DB_MAX_IDLE_CONNS", &c.MaxIdleConns},
+	}
+	for _, item := range ints {
+		if v := getenv(item.key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &ConfigError{Field: item.key, Reason: "must be an integer"}
+			}
+			*item.dst = n
+		}
+	}
+
+	durations := []struct {
+		key string
+		dst *Duration
+	}{
+		{"DB_CONN_MAX_LIFETIME", &c.ConnMaxLifetime},
+		{"DB_CONN_MAX_IDLE_TIME", &c.ConnMaxIdleTime},
+	}
+	for _, item := range durations {
+		if v := getenv(item.key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return &ConfigError{Field: item.key, Reason: "must be a duration like 30m"}
+			}
+			*item.dst = Duration(d)
+		}
+	}
+	return nil
+}
+
+// Validate -> cek config sebelum dipakai untuk koneksi
+func (c Config) Validate() error {
+	if _, err := c.dialector(); err != nil {
+		return err
+	}
+	if c.DSN == "" {
+		return &ConfigError{Field: "dsn", Reason: "is required"}
+	}
+	if c.MaxOpenConns < 0 {
+		return &ConfigError{Field: "max_open_conns", Reason: "must not be negative"}
+	}
+	if c.MaxIdleConns < 0 {
+		return &ConfigError{Field: "max_idle_conns", Reason: "must not be negative"}
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		return &ConfigError{Field: "max_idle_conns", Reason: "must not exceed max_open_conns"}
+	}
+	if c.ConnMaxLifetime < 0 {
+		return &ConfigError{Field: "conn_max_lifetime", Reason: "must not be negative"}
+	}
+	if c.ConnMaxIdleTime < 0 {
+		return &ConfigError{Field: "conn_max_idle_time", Reason: "must not be negative"}
+	}
+	if _, err := c.logLevel(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c Config) dialector() (gorm.Dialector, error) {
+	switch strings.ToLower(c.Dialect) {
+	case DialectMySQL, "":
+		return mysql.Open(c.DSN), nil
+	case DialectSQLite, "sqlite3":
+		return sqlite.Open(sqliteDSN(c.DSN)), nil
+	case DialectPostgres, "postgresql":
+		return postgres.Open(c.DSN), nil
+	default:
+		return nil, &ConfigError{Field: "dialect", Reason: "must be one of mysql, sqlite, postgres"}
+	}
+}
+
+// sqliteDSN -> foreign key sqlite mati secara default dan PRAGMA hanya berlaku per koneksi,
+// jadi diaktifkan lewat DSN supaya setiap koneksi di pool ikut menegakkannya (kecuali DSN sudah mengaturnya)
+func sqliteDSN(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_foreign_keys=1"
+	}
+	return dsn + "?_foreign_keys=1"
+}
+
+// isMemorySQLite -> database sqlite in-memory hanya hidup di satu koneksi
+func (c Config) isMemorySQLite() bool {
+	dialect := strings.ToLower(c.Dialect)
+	return (dialect == DialectSQLite || dialect == "sqlite3") && strings.Contains(c.DSN, ":memory:")
+}
+
+func (c Config) logLevel() (logger.LogLevel, error) {
+	switch strings.ToLower(c.LogLevel) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn", "warning":
+		return logger.Warn, nil
+	case "info", "":
+		return logger.Info, nil
+	default:
+		return 0, &ConfigError{Field: "log_level", Reason: "must be one of silent, error, warn, info"}
+	}
+}
+
+// Connect -> membuka koneksi gorm sesuai config, lalu ping dengan ctx
+func Connect(ctx context.Context, cfg Config) (*gorm.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	level, _ := cfg.logLevel()
+	dialector, _ := cfg.dialector()
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         logger.Default.LogMode(level), //memberikan logger
+		TranslateError: true,                          //error dari driver (misal duplicate key) diubah menjadi gorm.ErrDuplicatedKey dll
+	})
+	if err != nil {
+		return nil, &ConnectError{Op: "open", Err: err}
+	}
+	//gorm juga bisa menggunakan connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, &ConnectError{Op: "pool", Err: err}
+	}
+	//pool sudah terbuka sejak gorm.Open, setiap error setelah ini harus menutupnya
+	fail := func(op string, err error) (*gorm.DB, error) {
+		sqlDB.Close()
+		return nil, &ConnectError{Op: op, Err: err}
+	}
+	if err := setupJoinTables(db); err != nil {
+		return fail("join table", err)
+	}
+	if err := db.Use(OptimisticLock{}); err != nil {
+		return fail("plugin", err)
+	}
+	if cfg.Audit {
+		if err := db.Use(Audit{}); err != nil {
+			return fail("plugin", err)
+		}
+	}
+
+	if cfg.isMemorySQLite() {
+		//setiap koneksi baru ke ":memory:" adalah database kosong yang berbeda
+		cfg.MaxOpenConns, cfg.MaxIdleConns = 1, 1
+		cfg.ConnMaxLifetime, cfg.ConnMaxIdleTime = 0, 0
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime))
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime))
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fail("ping", err)
+	}
+
+	return db, nil
+}
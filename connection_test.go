@@ -0,0 +1,111 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigFileYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.yaml")
+	err := os.WriteFile(path, []byte("dsn: user:pass@tcp(db:3306)/app\nmax_open_conns: 20\nmax_idle_conns: 5\nconn_max_lifetime: 1h\nlog_level: warn\n"), 0o600)
+	assert.Nil(t, err)
+
+	cfg, err := LoadConfigFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "user:pass@tcp(db:3306)/app", cfg.DSN)
+	assert.Equal(t, 20, cfg.MaxOpenConns)
+	assert.Equal(t, 5, cfg.MaxIdleConns)
+	assert.Equal(t, Duration(time.Hour), cfg.ConnMaxLifetime)
+	assert.Equal(t, Duration(5*time.Minute), cfg.ConnMaxIdleTime) //tidak diisi, jadi memakai default
+	assert.Equal(t, "warn", cfg.LogLevel)
+}
+
+func TestLoadConfigFileJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	err := os.WriteFile(path, []byte(`{"dsn": "root:@tcp(localhost:3306)/other", "conn_max_idle_time": "90s"}`), 0o600)
+	assert.Nil(t, err)
+
+	cfg, err := LoadConfigFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "root:@tcp(localhost:3306)/other", cfg.DSN)
+	assert.Equal(t, Duration(90*time.Second), cfg.ConnMaxIdleTime)
+	assert.Equal(t, 100, cfg.MaxOpenConns)
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("DB_DSN", "root:@tcp(127.0.0.1:3307)/golang_gorm")
+	t.Setenv("DB_MAX_OPEN_CONNS", "7")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "10m")
+	t.Setenv("DB_LOG_LEVEL", "silent")
+
+	cfg, err := LoadConfigFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, "root:@tcp(127.0.0.1:3307)/golang_gorm", cfg.DSN)
+	assert.Equal(t, 7, cfg.MaxOpenConns)
+	assert.Equal(t, Duration(10*time.Minute), cfg.ConnMaxLifetime)
+	assert.Equal(t, "silent", cfg.LogLevel)
+
+	t.Setenv("DB_MAX_IDLE_CONNS", "banyak")
+	_, err = LoadConfigFromEnv()
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Nil(t, cfg.Validate())
+
+	cfg.MaxIdleConns = cfg.MaxOpenConns + 1
+	var configErr *ConfigError
+	assert.True(t, errors.As(cfg.Validate(), &configErr))
+	assert.Equal(t, "max_idle_conns", configErr.Field)
+
+	cfg = DefaultConfig()
+	cfg.ConnMaxLifetime = Duration(-time.Second)
+	assert.True(t, errors.Is(cfg.Validate(), ErrInvalidConfig))
+
+	cfg = DefaultConfig()
+	cfg.LogLevel = "debug"
+	assert.True(t, errors.Is(cfg.Validate(), ErrInvalidConfig))
+}
+
+func TestConnectInvalidConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DSN = ""
+
+	db, err := Connect(context.Background(), cfg)
+	assert.Nil(t, db)
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+}
+
+func TestConnectSQLiteForeignKeys(t *testing.T) {
+	assert.Equal(t, "app.db?_foreign_keys=1", sqliteDSN("app.db"))
+	assert.Equal(t, "app.db?_journal_mode=WAL&_foreign_keys=1", sqliteDSN("app.db?_journal_mode=WAL"))
+	assert.Equal(t, "app.db?_fk=0", sqliteDSN("app.db?_fk=0"))
+
+	cfg := DefaultConfig()
+	cfg.Dialect = DialectSQLite
+	cfg.DSN = filepath.Join(t.TempDir(), "fk.db")
+	cfg.LogLevel = "silent"
+	db, err := Connect(context.Background(), cfg)
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	defer sqlDB.Close()
+
+	//setiap koneksi di pool, bukan hanya koneksi pertama
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		assert.Nil(t, err)
+		defer conn.Close()
+		var enabled bool
+		assert.Nil(t, conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled))
+		assert.True(t, enabled)
+	}
+}
@@ -0,0 +1,73 @@
+package golanggorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func openSQLiteMemory(t *testing.T) *gorm.DB {
+	cfg := DefaultConfig()
+	cfg.Dialect = DialectSQLite
+	cfg.DSN = "file::memory:"
+	cfg.LogLevel = "silent"
+
+	db, err := Connect(context.Background(), cfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
+
+func TestConnectUnknownDialect(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Dialect = "oracle"
+
+	_, err := Connect(context.Background(), cfg)
+	var configErr *ConfigError
+	assert.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "dialect", configErr.Field)
+}
+
+func TestSQLiteAllModels(t *testing.T) {
+	db := openSQLiteMemory(t)
+	assert.Nil(t, db.AutoMigrate(Models()...))
+	assert.True(t, db.Migrator().HasTable("user_like_product"))
+
+	product := Product{Name: "Contoh Product", Price: 1000000}
+	assert.Nil(t, db.Create(&product).Error)
+
+	user := User{
+		Password:     "rahasia",
+		Name:         Name{FirstName: "Gojo", LastName: "Satoru"},
+		Wallet:       Wallet{Balance: 1000000},
+		Addresses:    []Address{{Address: "Jalan A"}, {Address: "Jalan B"}},
+		LikeProducts: []Product{product},
+	}
+	assert.Nil(t, db.Create(&user).Error)
+
+	var loaded User
+	err := db.Preload(clause.Associations).Take(&loaded, "id = ?", user.ID).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000), loaded.Wallet.Balance)
+	assert.Equal(t, 2, len(loaded.Addresses))
+	assert.Equal(t, 1, len(loaded.LikeProducts))
+
+	var count int64
+	err = db.Model(&User{}).Joins("Wallet").
+		Where(clause.Gt{Column: clause.Column{Table: "Wallet", Name: "balance"}, Value: 500000}).
+		Count(&count).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	todo := Todo{UserId: user.ID, Title: "Todo 1"}
+	assert.Nil(t, db.Create(&todo).Error)
+	assert.Nil(t, db.Delete(&todo).Error)
+	assert.Nil(t, db.Model(&Todo{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
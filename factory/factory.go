@@ -0,0 +1,223 @@
+// Package factory membuat data User/Wallet/Address/Product yang valid untuk test,
+// memakai random generator dengan seed supaya hasilnya selalu sama.
+//
+//	user, err := factory.User().WithWallet(1000).WithAddresses(3).WithLikedProducts(5).Create(db)
+package factory
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	golanggorm "golang-gorm"
+	"gorm.io/gorm"
+)
+
+var (
+	firstNames  = []string{"Gojo", "Nanami", "Kento", "Megumi", "Yuji", "Nobara", "Maki", "Toge", "Panda", "Yuta", "Suguru", "Toji", "Laksa", "Giyuu", "Tanjiro"}
+	middleNames = []string{"", "", "Satoru", "Fushiguro", "Itadori", "Kugisaki", "Zenin", "Okkotsu"}
+	lastNames   = []string{"", "Aji", "Geto", "Nanami", "Inumaki", "Kamado", "Tomioka", "Fushiguro"}
+	streets     = []string{"Jalan Merdeka", "Jalan Sudirman", "Jalan Thamrin", "Jalan Gatot Subroto", "Jalan Diponegoro", "Jalan Asia Afrika"}
+	cities      = []string{"Jakarta", "Bandung", "Surabaya", "Yogyakarta", "Medan", "Makassar"}
+	adjectives  = []string{"Premium", "Hemat", "Super", "Mini", "Pro", "Classic"}
+	items       = []string{"Kopi", "Teh", "Laptop", "Sepatu", "Kaos", "Buku", "Headphone", "Tas"}
+)
+
+// Factory -> sumber data random, aman dipakai dari beberapa goroutine
+type Factory struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+	seq int
+}
+
+// New -> Factory dengan seed tertentu, seed yang sama menghasilkan data yang sama
+func New(seed int64) *Factory {
+	return &Factory{rng: rand.New(rand.NewSource(seed))}
+}
+
+var (
+	defaultMu      sync.Mutex
+	defaultFactory = New(1)
+)
+
+// Seed -> mengganti seed factory default yang dipakai oleh User() dan Product()
+func Seed(seed int64) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultFactory = New(seed)
+}
+
+func current() *Factory {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultFactory
+}
+
+// User -> builder user dari factory default
+func User() *UserBuilder {
+	return current().User()
+}
+
+// Product -> builder product dari factory default
+func Product() *ProductBuilder {
+	return current().Product()
+}
+
+func (f *Factory) pick(values []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return values[f.rng.Intn(len(values))]
+}
+
+func (f *Factory) intn(n int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rng.Intn(n)
+}
+
+func (f *Factory) next() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	return f.seq
+}
+
+// UserBuilder -> menyusun User beserta relasinya sebelum disimpan
+type UserBuilder struct {
+	factory       *Factory
+	wallet        *int64
+	addresses     int
+	likedProducts int
+	overrides     []func(*golanggorm.User)
+}
+
+// User -> builder user dari factory ini
+func (f *Factory) User() *UserBuilder {
+	return &UserBuilder{factory: f}
+}
+
+// WithWallet -> user punya wallet dengan saldo balance
+func (b *UserBuilder) WithWallet(balance int64) *UserBuilder {
+	b.wallet = &balance
+	return b
+}
+
+// WithAddresses -> user punya n alamat
+func (b *UserBuilder) WithAddresses(n int) *UserBuilder {
+	b.addresses = n
+	return b
+}
+
+// WithLikedProducts -> user menyukai n product baru
+func (b *UserBuilder) WithLikedProducts(n int) *UserBuilder {
+	b.likedProducts = n
+	return b
+}
+
+// With -> mengubah field user setelah dibuat oleh factory (override)
+func (b *UserBuilder) With(override func(user *golanggorm.User)) *UserBuilder {
+	b.overrides = append(b.overrides, override)
+	return b
+}
+
+// Build -> membuat User tanpa menyimpan ke database
+func (b *UserBuilder) Build() golanggorm.User {
+	f := b.factory
+	seq := f.next()
+	user := golanggorm.User{
+		Password: fmt.Sprintf("rahasia-%d", seq),
+		Name: golanggorm.Name{
+			FirstName:  f.pick(firstNames),
+			MiddleName: f.pick(middleNames),
+			LastName:   f.pick(lastNames),
+		},
+	}
+	if b.wallet != nil {
+		user.Wallet = golanggorm.Wallet{Balance: *b.wallet}
+	}
+	for i := 0; i < b.addresses; i++ {
+		user.Addresses = append(user.Addresses, golanggorm.Address{
+			Address: fmt.Sprintf("%s No. %d, %s", f.pick(streets), f.intn(200)+1, f.pick(cities)),
+		})
+	}
+	for i := 0; i < b.likedProducts; i++ {
+		user.LikeProducts = append(user.LikeProducts, f.Product().Build())
+	}
+	for _, override := range b.overrides {
+		override(&user)
+	}
+	return user
+}
+
+// Create -> menyimpan user beserta wallet, alamat dan product yang disukai
+func (b *UserBuilder) Create(db *gorm.DB) (*golanggorm.User, error) {
+	user := b.Build()
+	if err := db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateMany -> menyimpan n user dengan CreateInBatches
+func (b *UserBuilder) CreateMany(db *gorm.DB, n, batchSize int) ([]golanggorm.User, error) {
+	users := make([]golanggorm.User, 0, n)
+	for i := 0; i < n; i++ {
+		users = append(users, b.Build())
+	}
+	if err := db.CreateInBatches(&users, batchSize).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ProductBuilder -> menyusun Product sebelum disimpan
+type ProductBuilder struct {
+	factory   *Factory
+	overrides []func(*golanggorm.Product)
+}
+
+// Product -> builder product dari factory ini
+func (f *Factory) Product() *ProductBuilder {
+	return &ProductBuilder{factory: f}
+}
+
+// With -> mengubah field product setelah dibuat oleh factory (override)
+func (b *ProductBuilder) With(override func(product *golanggorm.Product)) *ProductBuilder {
+	b.overrides = append(b.overrides, override)
+	return b
+}
+
+// Build -> membuat Product tanpa menyimpan ke database
+func (b *ProductBuilder) Build() golanggorm.Product {
+	f := b.factory
+	product := golanggorm.Product{
+		Name:  fmt.Sprintf("%s %s %d", f.pick(items), f.pick(adjectives), f.next()),
+		Price: int64(f.intn(1000)+1) * 1000,
+		Stock: f.intn(100) + 1,
+	}
+	for _, override := range b.overrides {
+		override(&product)
+	}
+	return product
+}
+
+// Create -> menyimpan satu product
+func (b *ProductBuilder) Create(db *gorm.DB) (*golanggorm.Product, error) {
+	product := b.Build()
+	if err := db.Create(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateMany -> menyimpan n product dengan CreateInBatches
+func (b *ProductBuilder) CreateMany(db *gorm.DB, n, batchSize int) ([]golanggorm.Product, error) {
+	products := make([]golanggorm.Product, 0, n)
+	for i := 0; i < n; i++ {
+		products = append(products, b.Build())
+	}
+	if err := db.CreateInBatches(&products, batchSize).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,97 @@
+package factory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	golanggorm "golang-gorm"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	golanggorm.PasswordCost = bcrypt.MinCost
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	cfg := golanggorm.DefaultConfig()
+	cfg.Dialect = golanggorm.DialectSQLite
+	cfg.DSN = "file::memory:"
+	cfg.LogLevel = "silent"
+
+	db, err := golanggorm.Connect(context.Background(), cfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	assert.Nil(t, golanggorm.Migrate(context.Background(), db))
+	return db
+}
+
+func TestCreateUserGraph(t *testing.T) {
+	db := openDB(t)
+
+	user, err := New(42).User().WithWallet(1000).WithAddresses(3).WithLikedProducts(5).Create(db)
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, user.ID)
+
+	var loaded golanggorm.User
+	err = db.Preload(clause.Associations).Take(&loaded, "id = ?", user.ID).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), loaded.Wallet.Balance)
+	assert.Equal(t, 3, len(loaded.Addresses))
+	assert.Equal(t, 5, len(loaded.LikeProducts))
+	assert.NotEqual(t, "", loaded.Name.FirstName)
+}
+
+func TestSameSeedSameData(t *testing.T) {
+	first := New(7).User().WithAddresses(2).Build()
+	second := New(7).User().WithAddresses(2).Build()
+	assert.Equal(t, first.Name, second.Name)
+	assert.Equal(t, first.Addresses, second.Addresses)
+
+	Seed(7)
+	assert.Equal(t, first.Name, User().Build().Name)
+}
+
+func TestOverrides(t *testing.T) {
+	db := openDB(t)
+
+	user, err := New(1).User().WithWallet(0).With(func(user *golanggorm.User) {
+		user.Name.FirstName = "Gojo"
+		user.Password = "rahasia"
+		user.Wallet.Balance = 99
+	}).Create(db)
+	assert.Nil(t, err)
+	assert.Equal(t, "Gojo", user.Name.FirstName)
+
+	var wallet golanggorm.Wallet
+	assert.Nil(t, db.Take(&wallet, "user_id = ?", user.ID).Error)
+	assert.Equal(t, int64(99), wallet.Balance)
+
+	product, err := New(1).Product().With(func(product *golanggorm.Product) { product.Price = 5 }).Create(db)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), product.Price)
+}
+
+func TestCreateManyInBatches(t *testing.T) {
+	db := openDB(t)
+
+	users, err := New(3).User().WithWallet(500).CreateMany(db, 25, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 25, len(users))
+
+	var count int64
+	assert.Nil(t, db.Model(&golanggorm.Wallet{}).Count(&count).Error)
+	assert.Equal(t, int64(25), count)
+
+	products, err := New(3).Product().CreateMany(db, 12, 5)
+	assert.Nil(t, err)
+	for _, product := range products {
+		assert.NotEqual(t, 0, product.ID)
+		assert.True(t, product.Price > 0)
+	}
+}
@@ -0,0 +1,586 @@
+// Package fixture mengisi database dari file yaml/json yang dikelompokkan per nama tabel.
+//
+// Contoh isi file:
+//
+//	users:
+//	  - _ref: gojo
+//	    first_name: Gojo
+//	    Wallet:
+//	      balance: 100
+//	    Addresses:
+//	      - address: Jalan A
+//	    LikeProducts: [$product_1]
+//	products:
+//	  - _ref: product_1
+//	    name: Contoh Product
+//
+// Nilai string yang diawali "$" adalah referensi ke baris lain (berdasarkan _ref).
+// Untuk kolom biasa nilainya diganti primary key baris tersebut, untuk relasi diganti barisnya.
+// Gunakan "$$" untuk menulis string yang memang diawali "$".
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// RefKey -> key khusus di setiap baris untuk memberi nama baris tersebut
+const RefKey = "_ref"
+
+var (
+	ErrUnknownTable = errors.New("fixture: unknown table")
+	ErrUnknownField = errors.New("fixture: unknown column or relation")
+	ErrUnknownRef   = errors.New("fixture: unknown reference")
+	ErrDuplicateRef = errors.New("fixture: duplicate reference")
+	ErrCycle        = errors.New("fixture: tables depend on each other")
+)
+
+// Rows -> isi satu file: nama tabel -> daftar baris
+type Rows map[string][]map[string]interface{}
+
+type created struct {
+	table string
+	value reflect.Value // pointer ke struct model
+}
+
+type pendingAssociation struct {
+	owner reflect.Value
+	rel   *schema.Relationship
+	items []interface{}
+}
+
+// Loader -> memasukkan fixture untuk model-model yang didaftarkan
+type Loader struct {
+	db      *gorm.DB
+	schemas map[string]*schema.Schema
+	refs    map[string]created
+}
+
+// New -> membuat Loader untuk models, tabel dicari dari TableName() masing-masing model
+func New(db *gorm.DB, models ...interface{}) (*Loader, error) {
+	loader := &Loader{db: db, schemas: map[string]*schema.Schema{}, refs: map[string]created{}}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("fixture: parse %T: %w", model, err)
+		}
+		loader.schemas[stmt.Schema.Table] = stmt.Schema
+	}
+	return loader, nil
+}
+
+// LoadFiles -> membaca lalu memasukkan semua file (.yaml, .yml, .json) dalam satu transaksi
+func (l *Loader) LoadFiles(ctx context.Context, paths ...string) error {
+	var all []Rows
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rows, err := Parse(data, filepath.Ext(path))
+		if err != nil {
+			return fmt.Errorf("fixture: %s: %w", path, err)
+		}
+		all = append(all, rows)
+	}
+	return l.Load(ctx, all...)
+}
+
+// Parse -> mengubah isi file menjadi Rows, format adalah ekstensi file (yaml, yml atau json)
+func Parse(data []byte, format string) (Rows, error) {
+	rows := Rows{}
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "json":
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, err
+		}
+		normalizeJSON(rows)
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &rows); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return rows, nil
+}
+
+// Load -> memasukkan baris secara berurutan sesuai ketergantungan antar tabel, dalam satu transaksi
+func (l *Loader) Load(ctx context.Context, files ...Rows) error {
+	merged := Rows{}
+	for _, rows := range files {
+		for table, items := range rows {
+			if _, ok := l.schemas[table]; !ok {
+				return fmt.Errorf("%w: %s", ErrUnknownTable, table)
+			}
+			merged[table] = append(merged[table], items...)
+		}
+	}
+
+	order, err := l.loadOrder(merged)
+	if err != nil {
+		return err
+	}
+
+	refs := make(map[string]created, len(l.refs))
+	for name, ref := range l.refs {
+		refs[name] = ref
+	}
+
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		run := &run{loader: l, tx: tx, ctx: ctx, refs: refs}
+		for _, table := range order {
+			for _, row := range merged[table] {
+				if _, err := run.create(l.schemas[table], row, nil); err != nil {
+					return fmt.Errorf("fixture: %s: %w", table, err)
+				}
+			}
+		}
+		return run.associate()
+	})
+	if err != nil {
+		return err
+	}
+	l.refs = refs
+	return nil
+}
+
+// Ref -> pointer ke model yang dibuat dari baris dengan _ref = name
+func (l *Loader) Ref(name string) (interface{}, bool) {
+	ref, ok := l.refs[name]
+	if !ok {
+		return nil, false
+	}
+	return ref.value.Interface(), true
+}
+
+// ID -> primary key dari baris dengan _ref = name
+func (l *Loader) ID(name string) (interface{}, bool) {
+	ref, ok := l.refs[name]
+	if !ok {
+		return nil, false
+	}
+	return primaryKey(context.Background(), l.schemas[ref.table], ref.value), true
+}
+
+// IDs -> semua _ref dan primary key-nya untuk satu tabel
+func (l *Loader) IDs(table string) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, ref := range l.refs {
+		if ref.table == table {
+			result[name] = primaryKey(context.Background(), l.schemas[table], ref.value)
+		}
+	}
+	return result
+}
+
+// Reset -> menghapus semua baris di tabel yang didaftarkan (termasuk tabel many2many) dan melupakan semua _ref
+func (l *Loader) Reset(ctx context.Context) error {
+	order, err := l.loadOrder(nil)
+	if err != nil {
+		return err
+	}
+
+	var tables []string
+	seen := map[string]bool{}
+	for _, table := range order {
+		for _, rel := range l.schemas[table].Relationships.Relations {
+			if rel.JoinTable != nil && !seen[rel.JoinTable.Table] {
+				seen[rel.JoinTable.Table] = true
+				tables = append(tables, rel.JoinTable.Table)
+			}
+		}
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		tables = append(tables, order[i])
+	}
+
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if !tx.Migrator().HasTable(table) {
+				continue
+			}
+			if err := tx.Exec("DELETE FROM ?", clause.Table{Name: table}).Error; err != nil {
+				return fmt.Errorf("fixture: reset %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	l.refs = map[string]created{}
+	return nil
+}
+
+// loadOrder -> urutan tabel (topological sort): tabel yang dirujuk dimasukkan lebih dulu
+func (l *Loader) loadOrder(rows Rows) ([]string, error) {
+	deps := map[string]map[string]bool{}
+	addDep := func(table, dependsOn string) {
+		if table == dependsOn {
+			return
+		}
+		if _, ok := l.schemas[table]; !ok {
+			return
+		}
+		if _, ok := l.schemas[dependsOn]; !ok {
+			return
+		}
+		if deps[table] == nil {
+			deps[table] = map[string]bool{}
+		}
+		deps[table][dependsOn] = true
+	}
+
+	for table, s := range l.schemas {
+		for _, rel := range s.Relationships.Relations {
+			switch rel.Type {
+			case schema.BelongsTo:
+				addDep(table, rel.FieldSchema.Table)
+			case schema.HasOne, schema.HasMany:
+				addDep(rel.FieldSchema.Table, table)
+			}
+		}
+	}
+
+	//referensi "$nama" di kolom biasa juga membuat ketergantungan
+	refTables := map[string]string{}
+	for name, ref := range l.refs {
+		refTables[name] = ref.table
+	}
+	for table, items := range rows {
+		for _, row := range items {
+			collectRefNames(row, table, refTables)
+		}
+	}
+	for table, items := range rows {
+		s := l.schemas[table]
+		for _, row := range items {
+			for key, value := range row {
+				if findRelation(s, key) != nil {
+					continue
+				}
+				if name, ok := refName(value); ok {
+					if refTable, ok := refTables[name]; ok {
+						addDep(table, refTable)
+					}
+				}
+			}
+		}
+	}
+
+	tables := make([]string, 0, len(l.schemas))
+	for table := range l.schemas {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var (
+		order   []string
+		state   = map[string]int{} // 1 = sedang dikunjungi, 2 = selesai
+		visit   func(table string) error
+		visited = func(table string) bool { return state[table] == 2 }
+	)
+	visit = func(table string) error {
+		switch state[table] {
+		case 1:
+			return fmt.Errorf("%w: %s", ErrCycle, table)
+		case 2:
+			return nil
+		}
+		state[table] = 1
+		dependsOn := make([]string, 0, len(deps[table]))
+		for dep := range deps[table] {
+			dependsOn = append(dependsOn, dep)
+		}
+		sort.Strings(dependsOn)
+		for _, dep := range dependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[table] = 2
+		order = append(order, table)
+		return nil
+	}
+	for _, table := range tables {
+		if !visited(table) {
+			if err := visit(table); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return order, nil
+}
+
+// collectRefNames -> _ref di baris bersarang ikut dibuat bersama baris paling atasnya
+func collectRefNames(row map[string]interface{}, table string, refTables map[string]string) {
+	if name, ok := row[RefKey].(string); ok {
+		refTables[name] = table
+	}
+	for _, value := range row {
+		for _, item := range asList(value) {
+			if nested, ok := item.(map[string]interface{}); ok {
+				collectRefNames(nested, table, refTables)
+			}
+		}
+	}
+}
+
+type run struct {
+	loader  *Loader
+	tx      *gorm.DB
+	ctx     context.Context
+	refs    map[string]created
+	pending []pendingAssociation
+}
+
+// create -> membuat satu baris beserta relasi bersarang, fixed berisi foreign key dari parent
+func (r *run) create(s *schema.Schema, row map[string]interface{}, fixed map[*schema.Field]interface{}) (reflect.Value, error) {
+	value := reflect.New(s.ModelType)
+	elem := value.Elem()
+
+	var (
+		ref       string
+		relations []struct {
+			rel  *schema.Relationship
+			data interface{}
+		}
+	)
+
+	keys := make([]string, 0, len(row))
+	for key := range row {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		data := row[key]
+		if key == RefKey {
+			name, ok := data.(string)
+			if !ok || name == "" {
+				return value, fmt.Errorf("%s must be a non-empty string", RefKey)
+			}
+			if _, exists := r.refs[name]; exists {
+				return value, fmt.Errorf("%w: %s", ErrDuplicateRef, name)
+			}
+			ref = name
+			continue
+		}
+
+		if rel := findRelation(s, key); rel != nil {
+			relations = append(relations, struct {
+				rel  *schema.Relationship
+				data interface{}
+			}{rel, data})
+			continue
+		}
+
+		field := s.LookUpField(key)
+		if field == nil || field.DBName == "" {
+			return value, fmt.Errorf("%w: %s.%s", ErrUnknownField, s.Table, key)
+		}
+		resolved, err := r.resolveColumn(data)
+		if err != nil {
+			return value, err
+		}
+		if err := field.Set(r.ctx, elem, resolved); err != nil {
+			return value, fmt.Errorf("set %s.%s: %w", s.Table, key, err)
+		}
+	}
+
+	//belongs to harus ada lebih dulu supaya foreign key bisa diisi
+	for _, item := range relations {
+		if item.rel.Type != schema.BelongsTo {
+			continue
+		}
+		owner, err := r.resolveRow(item.rel.FieldSchema, item.data)
+		if err != nil {
+			return value, fmt.Errorf("%s: %w", item.rel.Name, err)
+		}
+		for _, reference := range item.rel.References {
+			key, _ := reference.PrimaryKey.ValueOf(r.ctx, owner.Elem())
+			if err := reference.ForeignKey.Set(r.ctx, elem, key); err != nil {
+				return value, err
+			}
+		}
+	}
+
+	for field, data := range fixed {
+		if err := field.Set(r.ctx, elem, data); err != nil {
+			return value, err
+		}
+	}
+
+	if err := r.tx.Omit(clause.Associations).Create(value.Interface()).Error; err != nil {
+		return value, err
+	}
+	if ref != "" {
+		r.refs[ref] = created{table: s.Table, value: value}
+	}
+
+	for _, item := range relations {
+		switch item.rel.Type {
+		case schema.HasOne, schema.HasMany:
+			childFixed := map[*schema.Field]interface{}{}
+			for _, reference := range item.rel.References {
+				if reference.OwnPrimaryKey {
+					childFixed[reference.ForeignKey], _ = reference.PrimaryKey.ValueOf(r.ctx, elem)
+				} else if reference.PrimaryValue != "" {
+					childFixed[reference.ForeignKey] = reference.PrimaryValue //relasi polymorphic
+				}
+			}
+			for _, child := range asList(item.data) {
+				childRow, ok := child.(map[string]interface{})
+				if !ok {
+					return value, fmt.Errorf("%s: nested %s must be an object", s.Table, item.rel.Name)
+				}
+				if _, err := r.create(item.rel.FieldSchema, childRow, childFixed); err != nil {
+					return value, fmt.Errorf("%s: %w", item.rel.Name, err)
+				}
+			}
+		case schema.Many2Many:
+			//dijalankan setelah semua baris dibuat, supaya referensi ke tabel lain sudah ada
+			r.pending = append(r.pending, pendingAssociation{owner: value, rel: item.rel, items: asList(item.data)})
+		}
+	}
+	return value, nil
+}
+
+func (r *run) associate() error {
+	for _, pending := range r.pending {
+		var values []interface{}
+		for _, item := range pending.items {
+			target, err := r.resolveRow(pending.rel.FieldSchema, item)
+			if err != nil {
+				return fmt.Errorf("fixture: %s: %w", pending.rel.Name, err)
+			}
+			values = append(values, target.Interface())
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := r.tx.Model(pending.owner.Interface()).Association(pending.rel.Name).Append(values...); err != nil {
+			return fmt.Errorf("fixture: %s: %w", pending.rel.Name, err)
+		}
+	}
+	return nil
+}
+
+// resolveRow -> "$nama" menjadi baris yang sudah ada, object menjadi baris baru
+func (r *run) resolveRow(s *schema.Schema, data interface{}) (reflect.Value, error) {
+	if name, ok := refName(data); ok {
+		ref, ok := r.refs[name]
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownRef, name)
+		}
+		if ref.table != s.Table {
+			return reflect.Value{}, fmt.Errorf("%w: %s is a %s row, expected %s", ErrUnknownRef, name, ref.table, s.Table)
+		}
+		return ref.value, nil
+	}
+	row, ok := data.(map[string]interface{})
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("expected reference or object, got %T", data)
+	}
+	return r.create(s, row, nil)
+}
+
+// resolveColumn -> "$nama" menjadi primary key baris tersebut
+func (r *run) resolveColumn(data interface{}) (interface{}, error) {
+	if text, ok := data.(string); ok && strings.HasPrefix(text, "$$") {
+		return text[1:], nil
+	}
+	name, ok := refName(data)
+	if !ok {
+		return data, nil
+	}
+	ref, ok := r.refs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRef, name)
+	}
+	return primaryKey(r.ctx, r.loader.schemas[ref.table], ref.value), nil
+}
+
+func refName(data interface{}) (string, bool) {
+	text, ok := data.(string)
+	if !ok || !strings.HasPrefix(text, "$") || strings.HasPrefix(text, "$$") || len(text) == 1 {
+		return "", false
+	}
+	return text[1:], true
+}
+
+func findRelation(s *schema.Schema, key string) *schema.Relationship {
+	if rel, ok := s.Relationships.Relations[key]; ok {
+		return rel
+	}
+	for name, rel := range s.Relationships.Relations {
+		if strings.EqualFold(name, key) || (schema.NamingStrategy{}).ColumnName("", name) == key {
+			return rel
+		}
+	}
+	return nil
+}
+
+func primaryKey(ctx context.Context, s *schema.Schema, value reflect.Value) interface{} {
+	if s == nil || s.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	key, _ := s.PrioritizedPrimaryField.ValueOf(ctx, value.Elem())
+	return key
+}
+
+func asList(data interface{}) []interface{} {
+	if list, ok := data.([]interface{}); ok {
+		return list
+	}
+	if data == nil {
+		return nil
+	}
+	return []interface{}{data}
+}
+
+// normalizeJSON -> json.Number menjadi int64 atau float64
+func normalizeJSON(rows Rows) {
+	for _, items := range rows {
+		for _, row := range items {
+			for key, value := range row {
+				row[key] = normalizeJSONValue(value)
+			}
+		}
+	}
+}
+
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeJSONValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeJSONValue(item)
+		}
+	}
+	return value
+}
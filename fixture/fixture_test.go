@@ -0,0 +1,144 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	golanggorm "golang-gorm"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func init() {
+	golanggorm.PasswordCost = bcrypt.MinCost
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	cfg := golanggorm.DefaultConfig()
+	cfg.Dialect = golanggorm.DialectSQLite
+	cfg.DSN = "file::memory:"
+	cfg.LogLevel = "silent"
+
+	db, err := golanggorm.Connect(context.Background(), cfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	assert.Nil(t, golanggorm.Migrate(context.Background(), db))
+	return db
+}
+
+const usersJSON = `{
+  "users": [
+    {
+      "_ref": "gojo",
+      "first_name": "Gojo",
+      "password": "rahasia",
+      "Wallet": {"_ref": "gojo_wallet", "balance": 100},
+      "Addresses": [{"address": "Jalan A"}, {"address": "Jalan B"}],
+      "LikeProducts": ["$product_1", {"_ref": "product_2", "name": "Product 2", "price": 2000}]
+    }
+  ]
+}`
+
+const productsYAML = `
+products:
+  - _ref: product_1
+    name: Product 1
+    price: 1000
+todos:
+  - _ref: todo_1
+    user_id: $gojo
+    title: $$ bukan referensi
+`
+
+func TestLoadNestedRelations(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.json")
+	productsFile := filepath.Join(dir, "products.yaml")
+	assert.Nil(t, os.WriteFile(usersFile, []byte(usersJSON), 0o600))
+	assert.Nil(t, os.WriteFile(productsFile, []byte(productsYAML), 0o600))
+
+	loader, err := New(db, golanggorm.Models()...)
+	assert.Nil(t, err)
+	assert.Nil(t, loader.LoadFiles(ctx, usersFile, productsFile))
+
+	gojoID, ok := loader.ID("gojo")
+	assert.True(t, ok)
+
+	var user golanggorm.User
+	err = db.Preload("Wallet").Preload("Addresses").Preload("LikeProducts").Take(&user, "id = ?", gojoID).Error
+	assert.Nil(t, err)
+	assert.Equal(t, "Gojo", user.Name.FirstName)
+	assert.Equal(t, int64(100), user.Wallet.Balance)
+	assert.Equal(t, 2, len(user.Addresses))
+	assert.Equal(t, 2, len(user.LikeProducts))
+
+	wallet, ok := loader.Ref("gojo_wallet")
+	assert.True(t, ok)
+	assert.Equal(t, user.Wallet.ID, wallet.(*golanggorm.Wallet).ID)
+
+	var todo golanggorm.Todo
+	assert.Nil(t, db.Take(&todo).Error)
+	assert.Equal(t, "$ bukan referensi", todo.Title)
+	assert.Equal(t, user.ID, todo.UserId) //"$gojo" diganti primary key user
+}
+
+func TestReset(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	rows, err := Parse([]byte(usersJSON), "json")
+	assert.Nil(t, err)
+	products, err := Parse([]byte(productsYAML), "yaml")
+	assert.Nil(t, err)
+
+	loader, err := New(db, golanggorm.Models()...)
+	assert.Nil(t, err)
+	assert.Nil(t, loader.Load(ctx, rows, products))
+
+	assert.Nil(t, loader.Reset(ctx))
+	for _, table := range []string{"users", "wallets", "addresses", "products", "user_like_product", "todos"} {
+		var count int64
+		assert.Nil(t, db.Table(table).Count(&count).Error)
+		assert.Equal(t, int64(0), count, table)
+	}
+	_, ok := loader.ID("gojo")
+	assert.False(t, ok)
+
+	//setelah reset, fixture yang sama bisa dimasukkan lagi
+	assert.Nil(t, loader.Load(ctx, rows, products))
+}
+
+func TestLoadErrors(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	loader, err := New(db, golanggorm.Models()...)
+	assert.Nil(t, err)
+
+	err = loader.Load(ctx, Rows{"invoices": {{"id": 1}}})
+	assert.True(t, errors.Is(err, ErrUnknownTable))
+
+	err = loader.Load(ctx, Rows{"users": {{"nickname": "gojo"}}})
+	assert.True(t, errors.Is(err, ErrUnknownField))
+
+	err = loader.Load(ctx, Rows{"users": {{"first_name": "Gojo", "LikeProducts": []interface{}{"$missing"}}}})
+	assert.True(t, errors.Is(err, ErrUnknownRef))
+
+	//gagal di tengah jalan, jadi semua baris di rollback
+	var count int64
+	assert.Nil(t, db.Model(&golanggorm.User{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	err = loader.Load(ctx, Rows{"users": {{"_ref": "a", "first_name": "A"}, {"_ref": "a", "first_name": "B"}}})
+	assert.True(t, errors.Is(err, ErrDuplicateRef))
+}
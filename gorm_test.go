@@ -4,45 +4,19 @@ import (
 	"testing"
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"gorm.io/gorm/logger"
 )
 
-func OpenConnection() *gorm.DB {
-	dialect := mysql.Open("root:@tcp(localhost:3306)/golang_gorm?charset=utf8mb4&parseTime=True&loc=Local")
-	db, err := gorm.Open(dialect, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),//memberikan logger
-	})
-	if err != nil {
-		panic(err)
-	}
-
-	//gorm juga bisa menggunakan connection pool
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetConnMaxLifetime(30 * time.Minute)
-	sqlDB.SetConnMaxIdleTime(5 * time.Minute)
-
-	return db
-}
-
-var db = OpenConnection()
-
 func TestOpenConnection(t *testing.T) {
+	db, _ := setupTestDB(t)
 	assert.NotNil(t, db)
 }
 
 func TestExecuteSQL(t *testing.T) {
+	db, _ := setupTestDB(t)
 	err := db.Exec("insert into sample(name) values (?)", "Giyuu").Error//"db.Exec" -> digunakan untuk melakukan manipulasi data
 	assert.Nil(t, err)
 
@@ -63,8 +37,9 @@ type Sample struct {
 }
 
 func TestRawSQL(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var sample Sample
-	err := db.Raw("select id, name from sample where id = ?", "1").Scan(&sample).Error
+	err := db.Raw("select id, name from sample where id = ?", seed.Samples["Eko"]).Scan(&sample).Error
 	assert.Nil(t, err)
 	assert.Equal(t, "Eko", sample.Name)
 
@@ -72,11 +47,12 @@ func TestRawSQL(t *testing.T) {
 	var samples []Sample
 	err = db.Raw("select id, name from sample").Scan(&samples).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 5, len(samples))
+	assert.Equal(t, len(seedSamples), len(samples))
 }
 
 //how to using Row
 func TestSqlRow(t *testing.T) {
+	db, _ := setupTestDB(t)
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close()
@@ -94,10 +70,11 @@ func TestSqlRow(t *testing.T) {
 			Name: name,
 		})
 	}
-	assert.Equal(t, 5, len(samples))
+	assert.Equal(t, len(seedSamples), len(samples))
 }
 
 func TestScanRow(t *testing.T) {//cara lebih mudah untuk melakukan iterasi dibanding TestSqlRow
+	db, _ := setupTestDB(t)
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close()
@@ -107,11 +84,12 @@ func TestScanRow(t *testing.T) {//cara lebih mudah untuk melakukan iterasi diban
 		err := db.ScanRows(rows, &samples)
 		assert.Nil(t, err)
 	}
-	assert.Equal(t, 5, len(samples))
+	assert.Equal(t, len(seedSamples), len(samples))
 }
 
 //create
 func TestCreateUser(t *testing.T) {
+	db, _ := setupTestDB(t)
 	user := User{
 		Password: "rahasia",
 		Name: Name{
@@ -128,6 +106,7 @@ func TestCreateUser(t *testing.T) {
 
 //batch insert -> berguna untuk memasukkan data yg banyak dalam sekali input
 func TestBatchInsert(t *testing.T) {
+	db, _ := setupTestDB(t)
 	var users []User
 	for i := 0; i < 2; i++ {
 		users = append(users, User{
@@ -144,6 +123,7 @@ func TestBatchInsert(t *testing.T) {
 
 //Transaction
 func TestTransactionSuccess(t *testing.T) {
+	db, _ := setupTestDB(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		err := tx.Create(&User{Password: "rahasialah", Name: Name{FirstName: "Nanami"}}).Error
 		if err != nil {
@@ -157,8 +137,10 @@ func TestTransactionSuccess(t *testing.T) {
 }
 
 func TestTransactionError(t *testing.T) {
+	db, seed := setupTestDB(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
-		err := tx.Create(&User{Password: "", Name: Name{FirstName: "Suguru"}}).Error
+		//id sudah dipakai, jadi insert gagal dan transaksi di rollback
+		err := tx.Create(&User{ID: seed.Users["suguru"], Password: "", Name: Name{FirstName: "Suguru"}}).Error
 		if err != nil {
 			return err
 		}
@@ -170,6 +152,7 @@ func TestTransactionError(t *testing.T) {
 }
 
 func TestManualTransactionSuccess(t *testing.T) {
+	db, _ := setupTestDB(t)
 	tx := db.Begin()
 	defer tx.Rollback()
 
@@ -182,6 +165,7 @@ func TestManualTransactionSuccess(t *testing.T) {
 }
 
 func TestManualTransactionError(t *testing.T) {
+	db, _ := setupTestDB(t)
 	tx := db.Begin()
 	defer tx.Rollback()
 
@@ -195,10 +179,11 @@ func TestManualTransactionError(t *testing.T) {
 
 //query
 func TestQuerySingleObject(t *testing.T) {
+	db, seed := setupTestDB(t)
 	user := User{}
 	err := db.First(&user).Error //untuk mengambil data yang pertama
 	assert.Nil(t, err)
-	assert.Equal(t, "1", user.ID)
+	assert.Equal(t, seed.Users["gojo"], user.ID)
 
 	// user = User{}
 	// err = db.Last(&user).Error //untuk mengambil data yang terakhir
@@ -207,45 +192,56 @@ func TestQuerySingleObject(t *testing.T) {
 }
 
 func TestQuerySingleObjectInlineCondition(t *testing.T) {
+	db, seed := setupTestDB(t)
 	user := User{}
 	// err := db.First(&user, "id = ?", "5").Error
-	err := db.Take(&user, "id = ?", "5").Error
+	err := db.Take(&user, "id = ?", seed.Users["nanami"]).Error
 	assert.Nil(t, err)
-	assert.Equal(t, "5", user.ID)
+	assert.Equal(t, seed.Users["nanami"], user.ID)
 	assert.Equal(t, "Nanami", user.Name.FirstName)
 }
 
 func TestQueryAllObjects(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
-	err := db.Find(&users, "id in ?", []string{"1", "2", "3", "4"}).Error
+	err := db.Find(&users, "id in ?", []int{seed.Users["gojo"], seed.Users["nanami"], seed.Users["laksa"], seed.Users["kento"]}).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 4, len(users))
 }
 
+//password di database sudah di hash, jadi contoh di bawah memakai id user yang password fixture-nya "rahasia"
+func rahasiaUsers(seed *testSeed) []int {
+	return []int{seed.Users["gojo"], seed.Users["nanami"], seed.Users["kento"], seed.Users["toji"], seed.Users["user_a"]}
+}
+
 //operator logika untuk query
 func TestQueryCondition(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
-	err := db.Where("first_name like ?", "%Gojo%").Where("password = ?", "rahasia").Find(&users).Error//&&
+	err := db.Where("first_name like ?", "%Gojo%").Where("id in ?", rahasiaUsers(seed)).Find(&users).Error//&&
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(users))
 }
 
 func TestOrOperator(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
-	err := db.Where("first_name like ?", "%User%").Or("password = ?", "rahasia").Find(&users).Error// |
+	err := db.Where("first_name like ?", "%User%").Or("id in ?", rahasiaUsers(seed)).Find(&users).Error// |
 	assert.Nil(t, err)
-	assert.Equal(t, 6, len(users))
+	assert.Equal(t, 7, len(users)) //User A, B, C ditambah 4 user lain dengan password "rahasia"
 }
 
 func TestNotOperator(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
-	err := db.Not("first_name like ?", "%User%").Where("password = ?", "rahasia").Find(&users).Error
+	err := db.Not("first_name like ?", "%User%").Where("id in ?", rahasiaUsers(seed)).Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 5, len(users))
+	assert.Equal(t, 4, len(users))
 }
 
 //select fields -> untuk menentukan kolom mana yg mau diambil
 func TestSelectFields(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
 	err := db.Select("id", "first_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -255,16 +251,17 @@ func TestSelectFields(t *testing.T) {
 		assert.NotEqual(t, "", user.Name.FirstName)
 	}
 
-	assert.Equal(t, 9, len(users))
+	assert.Equal(t, len(seed.Users), len(users))
 }
 
 func TestStructCondition(t *testing.T) {
+	db, _ := setupTestDB(t)
 	userCondition := User{
 		Name: Name{
 			FirstName: "Gojo",
-			// LastName:  "", // tidak bisa, karena dianggap default value(string kosong adalah default value)
+			LastName:  "Aji",
+			// MiddleName:  "", // tidak bisa, karena dianggap default value(string kosong adalah default value)
 		},
-		Password: "rahasia",
 	}
 
 	var users []User
@@ -274,6 +271,7 @@ func TestStructCondition(t *testing.T) {
 }
 
 func TestMapCondition(t *testing.T) {
+	db, _ := setupTestDB(t)
 	mapCondition := map[string]interface{}{
 		"middle_name": "", //kalau menggunakan map bisa melakukan pencarian string kosong(default value)
 		"last_name":   "", //kalau menggunakan map bisa melakukan pencarian string kosong(default value)
@@ -282,18 +280,19 @@ func TestMapCondition(t *testing.T) {
 	var users []User
 	err := db.Where(mapCondition).Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 7, len(users))
+	assert.Equal(t, 5, len(users))
 }
 
 //Order, Limit, Offset -> biasanya digunakan untuk melakukan pagination
 func TestOrderLimitOffset(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
 	//Untuk melakukan sorting, kita juga bisa menggunakan method Order()
 	//Dan untuk melakukan paging, kita bisa menggunakan method Limit() dan Offset()
 	//Offset -> berguna untuk skip misal, ingin skip berapa data
 	err := db.Order("id asc, first_name desc").Limit(5).Offset(5).Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 4, len(users))
+	assert.Equal(t, len(seed.Users)-5, len(users))
 }
 
 //query non model
@@ -304,17 +303,19 @@ type UserResponse struct {
 }
 
 func TestQueryNonModel(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []UserResponse
 	err := db.Model(&User{}).Select("id", "first_name", "last_name").Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 9, len(users))
+	assert.Equal(t, len(seed.Users), len(users))
 	fmt.Println(users)
 }
 
 //update
 func TestUpdate(t *testing.T) {//update tanpa memilih kolom untuk di update
+	db, seed := setupTestDB(t)
 	user := User{}
-	err := db.Take(&user, "id = ?", "3").Error
+	err := db.Take(&user, "id = ?", seed.Users["laksa"]).Error
 	assert.Nil(t, err)
 
 	user.Name.FirstName = "Laksa"
@@ -327,6 +328,7 @@ func TestUpdate(t *testing.T) {//update tanpa memilih kolom untuk di update
 }
 
 func TestUpdateSelectedColumns(t *testing.T) {// update memilih kolom untuk di update
+	db, seed := setupTestDB(t)
 	//menggunakan "Updates map"
 	// err := db.Model(&User{}).Where("id = ?", "9").Updates(map[string]interface{}{
 	// 	"middle_name": "",
@@ -339,7 +341,7 @@ func TestUpdateSelectedColumns(t *testing.T) {// update memilih kolom untuk di u
 	// assert.Nil(t, err)
 
 	//menggunakan "Updates struct"
-	err := db.Where("id = ?", "9").Updates(User{
+	err := db.Model(&User{}).Where("id = ?", seed.Users["megumi"]).Updates(User{//User punya hook, jadi perlu Model
 		Name: Name{
 			FirstName: "Kento",
 			LastName:  "Nanami",
@@ -350,9 +352,11 @@ func TestUpdateSelectedColumns(t *testing.T) {// update memilih kolom untuk di u
 
 //auto increment
 func TestAutoIncrement(t *testing.T) {
+	db, seed := setupTestDB(t)
+	userID := seed.Users["laksa"]
 	for i := 0; i < 10; i++ {
 		userLog := UserLog{
-			UserId: "3",
+			UserId: &userID,
 			Action: "Test Action3",
 		}
 
@@ -366,21 +370,24 @@ func TestAutoIncrement(t *testing.T) {
 
 //upsert (update atau insert)
 func TestSaveOrUpdate(t *testing.T) {
+	db, seed := setupTestDB(t)
+	userID := seed.Users["megumi"]
 	userLog := UserLog{
 		//ID: , //Tidak set id nya
-		UserId: "8",
+		UserId: &userID,
 		Action: "Test Action woy",
 	}
 
 	err := db.Save(&userLog).Error // insert //ceritanya tanpa memasukkan id sehingga terjadi create
 	assert.Nil(t, err)
 
-	userLog.UserId = "9" //ceritanya memasukkan id sehingga terjadi update
+	userID = seed.Users["suguru"] //ceritanya memasukkan id sehingga terjadi update
 	err = db.Save(&userLog).Error // update
 	assert.Nil(t, err)
 }
 
 func TestSaveOrUpdateNonAutoIncrement(t *testing.T) { //berguna untuk data yg non auto increment
+	db, _ := setupTestDB(t)
 //ket -> jika ingin melakukan update tapi id nya blum ada di db maka, golang akan melakukan create tapi jika id sudah ada maka, golang akan melakukan update
 	user := User{
 		ID: 10, //anggaplah id 10 ini belum ada di db (penulisannya harus string -> "10")
@@ -398,6 +405,7 @@ func TestSaveOrUpdateNonAutoIncrement(t *testing.T) { //berguna untuk data yg no
 }
 
 func TestConflict(t *testing.T) { //untuk primary key yg duplikat
+	db, _ := setupTestDB(t)
 //ket : jika id belum dibuat maka, golang akan melakukan insert(create) tapi jika ingin create id yg sudah ada maka, golang akan melakukan update
 	user := User{
 		ID: 10,
@@ -412,27 +420,29 @@ func TestConflict(t *testing.T) { //untuk primary key yg duplikat
 
 //Delete
 func TestDelete(t *testing.T) {
+	db, seed := setupTestDB(t)
 	//cara 1
 	var user User //find dulu ke db
-	err := db.Take(&user, "id = ?", "4").Error //find dulu ke db
+	err := db.Take(&user, "id = ?", seed.Users["user_b"]).Error //find dulu ke db
 	assert.Nil(t, err)
 
 	err = db.Delete(&user).Error //setelah find db nya maka, lakukan delete
 	assert.Nil(t, err)
 
 	//delete secara langsung tanpa melakukan find terlebih dahulu (cara 2)
-	err = db.Delete(&User{}, "id = ?", "5").Error
+	err = db.Delete(&User{}, "id = ?", seed.Users["megumi"]).Error
 	assert.Nil(t, err)
 
-	//delete secara langsung tanpa melakukan find terlebih dahulu tapi menggunakan "where" (cara 3) 4,5,7 yg kehapus
-	err = db.Where("id = ?", "7").Delete(&User{}).Error
+	//delete secara langsung tanpa melakukan find terlebih dahulu tapi menggunakan "where" (cara 3)
+	err = db.Where("id = ?", seed.Users["suguru"]).Delete(&User{}).Error
 	assert.Nil(t, err)
 }
 
 //soft delete
 func TestSoftDelete(t *testing.T) {
+	db, _ := setupTestDB(t)
 	todo := Todo{
-		UserId:      "5",
+		UserId:      5,
 		Title:       "Todo 5",
 		Description: "Description 5",
 	}
@@ -450,6 +460,7 @@ func TestSoftDelete(t *testing.T) {
 }
 
 func TestUnscoped(t *testing.T) {
+	db, _ := setupTestDB(t)
 //ket -> Kita kita ingin mengambil data termasuk yang sudah di soft delete, kita bisa gunakan method Unscoped()
 //ket -> Method Unscoped() juga bisa digunakan jika kita benar-benar mau melakukan hard delete permanen di database
 
@@ -468,9 +479,10 @@ func TestUnscoped(t *testing.T) {
 
 //Lock
 func TestLock(t *testing.T) {
+	db, seed := setupTestDB(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
-		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, "id = ?", "3").Error
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, "id = ?", seed.Users["laksa"]).Error
 		if err != nil {
 			return err
 		}
@@ -485,8 +497,9 @@ func TestLock(t *testing.T) {
 
 //one to one
 func TestCreateWallet(t *testing.T) {
+	db, seed := setupTestDB(t)
 	wallet := Wallet{
-		UserId:  1,
+		UserId:  seed.Users["suguru"],
 		Balance: 100,
 	}
 
@@ -495,27 +508,30 @@ func TestCreateWallet(t *testing.T) {
 }
 
 func TestRetrieveRelation(t *testing.T) {//untuk mengambil data sekaligus mengambil data yg berelasi. Mirip seperti "join"
+	db, seed := setupTestDB(t)
 	var user User
-	err := db.Model(&User{}).Preload("Wallet").Take(&user, "id = ?", "1").Error
+	err := db.Model(&User{}).Preload("Wallet").Take(&user, "id = ?", seed.Users["gojo"]).Error
 	assert.Nil(t, err)
 
-	assert.Equal(t, 1, user.ID)
-	assert.Equal(t, 1, user.Wallet.ID)
+	assert.Equal(t, seed.Users["gojo"], user.ID)
+	assert.Equal(t, seed.Wallets["gojo"], user.Wallet.ID)
 }
 
 func TestRetrieveRelationJoin(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
-	err := db.Model(&User{}).Joins("Wallet").Take(&user, "users.id = ?", "1").Error
+	err := db.Model(&User{}).Joins("Wallet").Take(&user, "users.id = ?", seed.Users["gojo"]).Error
 	//joins itu lebih cocok untuk relasi one to one
 	assert.Nil(t, err)
 
-	assert.Equal(t, 1, user.ID)
-	assert.Equal(t, 1, user.Wallet.ID)
+	assert.Equal(t, seed.Users["gojo"], user.ID)
+	assert.Equal(t, seed.Wallets["gojo"], user.Wallet.ID)
 	fmt.Println(user)
 }
 
 //auto upsert relation -> untuk update atau insert data relasi
 func TestAutoCreateUpdate(t *testing.T) {
+	db, seed := setupTestDB(t)
 	user := User{
 		// ID:       16,
 		Password: "rahasia broo",
@@ -523,7 +539,7 @@ func TestAutoCreateUpdate(t *testing.T) {
 			FirstName: "User rahasia",
 		},
 		Wallet: Wallet{ //kalau ada datanya akan melakukan update kalau tidak ada datanya akan melakukan insert
-			ID:      5,
+			ID:      seed.Wallets["toji"],
 			// UserId:  14,
 			Balance: 7000,
 		},
@@ -537,6 +553,7 @@ func TestAutoCreateUpdate(t *testing.T) {
 
 //skip auto upsert -> untuk skip data relasi yg tidak ingin di update atau insert
 func TestSkipAutoCreateUpdate(t *testing.T) {
+	db, _ := setupTestDB(t)
 	user := User{
 		// ID:       "21",
 		Password: "rahasia",
@@ -556,6 +573,7 @@ func TestSkipAutoCreateUpdate(t *testing.T) {
 
 //one to many
 func TestUserAndAddresses(t *testing.T) {
+	db, _ := setupTestDB(t)
 	user := User{
 		// ID:       "2",
 		Password: "rahasia 23",
@@ -584,6 +602,7 @@ func TestUserAndAddresses(t *testing.T) {
 }
 
 func TestPreloadJoinOneToMany(t *testing.T) {
+	db, _ := setupTestDB(t)
 	var users []User
 	err := db.Model(&User{}).Preload("Addresses").Joins("Wallet").Find(&users).Error
 	//preload lebih cocok digunakan untuk one to many atau many to many
@@ -591,14 +610,16 @@ func TestPreloadJoinOneToMany(t *testing.T) {
 }
 
 func TestTakePreloadJoinOneToMany(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
 	err := db.Model(&User{}).Preload("Addresses").Joins("Wallet").
-		Take(&user, "users.id = ?", "23").Error
+		Take(&user, "users.id = ?", seed.Users["user_a"]).Error
 	assert.Nil(t, err)
 }
 
 //many to one(belongs to)
 func TestBelongsTo(t *testing.T) {
+	db, _ := setupTestDB(t)
 	fmt.Println("Preload")
 	var addresses []Address
 	err := db.Model(&Address{}).Preload("User").Find(&addresses).Error
@@ -613,6 +634,7 @@ func TestBelongsTo(t *testing.T) {
 }
 
 func TestBelongsToWallet(t *testing.T) {//belongs to (one to one)
+	db, _ := setupTestDB(t)
 	fmt.Println("Preload")
 	var wallets []Wallet
 	err := db.Model(&Wallet{}).Preload("User").Find(&wallets).Error
@@ -626,6 +648,7 @@ func TestBelongsToWallet(t *testing.T) {//belongs to (one to one)
 
 //many to many
 func TestCreateManyToMany(t *testing.T) {
+	db, seed := setupTestDB(t)
 	// product := Product{ //flexible
 	// 	// ID:    "P001",
 	// 	Name:  "Contoh Product",
@@ -635,8 +658,8 @@ func TestCreateManyToMany(t *testing.T) {
 	// assert.Nil(t, err)
 
 	err := db.Table("user_like_product").Create(map[string]interface{}{
-		"user_id":    9,
-		"product_id": 3,
+		"user_id":    seed.Users["megumi"],
+		"product_id": seed.Products["product_2"],
 	}).Error
 	assert.Nil(t, err)
 	
@@ -649,23 +672,26 @@ func TestCreateManyToMany(t *testing.T) {
 }
 
 func TestPreloadManyToManyProduct(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var product Product
-	err := db.Preload("LikedByUsers").Take(&product, "id = ?", "1").Error
+	err := db.Preload("LikedByUsers").Take(&product, "id = ?", seed.Products["product_1"]).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(product.LikedByUsers))
 }
 
 func TestPreloadManyToManyUser(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
-	err := db.Preload("LikeProducts").Take(&user, "id = ?", "20").Error
+	err := db.Preload("LikeProducts").Take(&user, "id = ?", seed.Users["user_c"]).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(user.LikeProducts))
 }
 
 //cara mencari relasi(association mode)
 func TestAssociationFind(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var product Product
-	err := db.Take(&product, "id = ?", "3").Error//coba nanti id nya ganti 3
+	err := db.Take(&product, "id = ?", seed.Products["product_3"]).Error
 	assert.Nil(t, err)
 
 	var users []User
@@ -675,23 +701,26 @@ func TestAssociationFind(t *testing.T) {
 }
 
 func TestAssociationAppend(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
-	err := db.Take(&user, "id = ?", "2").Error
+	err := db.Take(&user, "id = ?", seed.Users["nanami"]).Error
 	assert.Nil(t, err)
 
 	var product Product
-	err = db.Take(&product, "id = ?", "3").Error
+	err = db.Take(&product, "id = ?", seed.Products["product_3"]).Error
 	assert.Nil(t, err)
 
 	err = db.Model(&product).Association("LikedByUsers").Append(&user)//append itu menambahkan
 	assert.Nil(t, err)
 }
 
-//replace(association mode) -> lebih cocok untuk relasi one to one dalam menggunakan association mode
+//replace(association mode) -> lebih cocok untuk relasi one to one dalam menggunakan association mode.
+//wallet baru di insert sebelum wallet lama dilepas, jadi user yang sudah punya wallet ditolak unique index idx_wallets_user_id
 func TestAssociationReplace(t *testing.T) {
+	db, seed := setupTestDB(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
-		err := tx.Take(&user, "id = ?", "3").Error
+		err := tx.Take(&user, "id = ?", seed.Users["user_b"]).Error
 		assert.Nil(t, err)
 
 		wallet := Wallet{
@@ -704,16 +733,22 @@ func TestAssociationReplace(t *testing.T) {
 		return err
 	})
 	assert.Nil(t, err)
+
+	var user User
+	err = db.Preload("Wallet").Take(&user, "id = ?", seed.Users["user_b"]).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10000), user.Wallet.Balance)
 }
 
 //delete(association mode) -> untuk menghapus relasi
 func TestAssociationDelete(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
-	err := db.Take(&user, "id = ?", "20").Error
+	err := db.Take(&user, "id = ?", seed.Users["user_c"]).Error
 	assert.Nil(t, err)
 
 	var product Product
-	err = db.Take(&product, "id = ?", "1").Error
+	err = db.Take(&product, "id = ?", seed.Products["product_1"]).Error
 	assert.Nil(t, err)
 
 	err = db.Model(&product).Association("LikedByUsers").Delete(&user)
@@ -721,8 +756,9 @@ func TestAssociationDelete(t *testing.T) {
 }
 
 func TestAssociationClear(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var product Product
-	err := db.Take(&product, "id = ?", "3").Error
+	err := db.Take(&product, "id = ?", seed.Products["product_3"]).Error
 	assert.Nil(t, err)
 
 	err = db.Model(&product).Association("LikedByUsers").Clear()
@@ -731,10 +767,11 @@ func TestAssociationClear(t *testing.T) {
 
 //preloading -> untuk melakukan loading relasi
 func TestPreloadingWithCondition(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
 	//mau ambil data Wallet nya tapi kalau balance nya lebih dari 100
 	//jadi kalau user yang memiliki id "1" itu tidak punya balance sebesar "100" maka, datanya tidak akan diambil
-	err := db.Preload("Wallet", "balance > ?", 90).Take(&user, "id = ?", "1").Error
+	err := db.Preload("Wallet", "balance > ?", 90).Take(&user, "id = ?", seed.Users["gojo"]).Error
 	assert.Nil(t, err)
 
 	fmt.Println(user)
@@ -742,8 +779,9 @@ func TestPreloadingWithCondition(t *testing.T) {
 
 //preloading nested
 func TestPreloadingNested(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var wallet Wallet
-	err := db.Preload("User.Addresses").Take(&wallet, "id = ?", "9").Error
+	err := db.Preload("User.Addresses").Take(&wallet, "id = ?", seed.Wallets["user_a"]).Error
 	assert.Nil(t, err)
 
 	fmt.Println(wallet)
@@ -754,41 +792,45 @@ func TestPreloadingNested(t *testing.T) {
 //preload all -> jika kita ingin melakukan preload semua relasi di model
 //namun perlu diingat kalau preload all itu tidak melakukan load nested relation
 func TestPreloadingAll(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var user User
-	err := db.Preload(clause.Associations).Take(&user, "id = ?", "23").Error
+	err := db.Preload(clause.Associations).Take(&user, "id = ?", seed.Users["user_a"]).Error
 	assert.Nil(t, err)
 }
 
 //Joins
 func TestJoinQuery(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var users []User
 	err := db.Joins("join wallets on wallets.user_id = users.id").Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 10, len(users))
+	assert.Equal(t, len(seed.Wallets), len(users))
 
 	//joins manual dan defaultnya adalah left join
 	users = []User{}
 	err = db.Joins("Wallet").Find(&users).Error // left join
 	assert.Nil(t, err)
-	assert.Equal(t, 20, len(users))
+	assert.Equal(t, len(seed.Users), len(users))
 }
 
 func TestJoinWithCondition(t *testing.T) {
+	db, _ := setupTestDB(t)
 	var users []User
 	err := db.Joins("join wallets on wallets.user_id = users.id AND wallets.balance > ?", 500000).Find(&users).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 3, len(users))
 
 	users = []User{}
-	err = db.Joins("Wallet").Where("Wallet.balance > ?", 500000).Find(&users).Error
+	err = db.Joins("Wallet").Where(clause.Gt{Column: clause.Column{Table: "Wallet", Name: "balance"}, Value: 500000}).Find(&users).Error //alias "Wallet" di-quote sesuai dialect
 	assert.Nil(t, err)
 	assert.Equal(t, 3, len(users))
 }
 
 //query agregation
 func TestCount(t *testing.T) {
+	db, _ := setupTestDB(t)
 	var count int64
-	err := db.Model(&User{}).Joins("Wallet").Where("Wallet.balance > ?", 500000).Count(&count).Error
+	err := db.Model(&User{}).Joins("Wallet").Where(clause.Gt{Column: clause.Column{Table: "Wallet", Name: "balance"}, Value: 500000}).Count(&count).Error
 	assert.Nil(t, err)
 	assert.Equal(t, int64(3), count)
 }
@@ -802,6 +844,7 @@ type AggregationResult struct {
 }
 
 func TestAggregation(t *testing.T) {
+	db, seed := setupTestDB(t)
 	var result AggregationResult
 	err := db.Model(&Wallet{}).Select("sum(balance) as total_balance", "min(balance) as min_balance",
 		"max(balance) as max_balance", "avg(balance) as avg_balance").Take(&result).Error
@@ -810,14 +853,15 @@ func TestAggregation(t *testing.T) {
 	assert.Equal(t, int64(3043100), result.TotalBalance)
 	assert.Equal(t, int64(100), result.MinBalance)
 	assert.Equal(t, int64(1000000), result.MaxBalance)
-	assert.Equal(t, float64(304310), result.AvgBalance)
+	assert.InDelta(t, float64(3043100)/float64(len(seed.Wallets)), result.AvgBalance, 0.01)
 }
 
 func TestAggregationGroupByAndHaving(t *testing.T) {
+	db, _ := setupTestDB(t)
 	var results []AggregationResult
 	err := db.Model(&Wallet{}).Select("sum(balance) as total_balance", "min(balance) as min_balance",
 		"max(balance) as max_balance", "avg(balance) as avg_balance").
-		Joins("User").Group("User.id").Having("sum(balance) > ?", 500000).
+		Joins("User").Clauses(clause.GroupBy{Columns: []clause.Column{{Table: "User", Name: "id"}}}).Having("sum(balance) > ?", 500000).
 		Find(&results).Error
 	assert.Nil(t, err)
 	assert.Equal(t, 3, len(results))
@@ -825,12 +869,13 @@ func TestAggregationGroupByAndHaving(t *testing.T) {
 
 //context
 func TestContext(t *testing.T) {
+	db, seed := setupTestDB(t)
 	ctx := context.Background()
 
 	var users []User
 	err := db.WithContext(ctx).Find(&users).Error
 	assert.Nil(t, err)
-	assert.Equal(t, 20, len(users))
+	assert.Equal(t, len(seed.Users), len(users))
 } 
 
 //scopes
@@ -843,6 +888,7 @@ func SultanWalletBalance(db *gorm.DB) *gorm.DB {
 }
 
 func TestScopes(t *testing.T) {
+	db, _ := setupTestDB(t)
 	var wallets []Wallet
 	err := db.Scopes(BrokeWalletBalance).Find(&wallets).Error
 	assert.Nil(t, err)
@@ -852,14 +898,54 @@ func TestScopes(t *testing.T) {
 	assert.Nil(t, err)
 }
 
-//migrator -> untuk migrasi(sangat tidak disarankan jika digunakan untuk real case)
+//migrator -> AutoMigrate sangat tidak disarankan untuk real case, gunakan migrasi yang berversi (lihat migrations.go)
 func TestMigrator(t *testing.T) {
-	err := db.Migrator().AutoMigrate(&GuestBook{})
+	db, _ := setupTestDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(db)
+	assert.Nil(t, err)
+
+	statuses, err := migrator.Status(ctx)
 	assert.Nil(t, err)
+	assert.Equal(t, len(Migrations()), len(statuses))
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+	}
+
+	//down lalu up lagi untuk migrasi terakhir, cek di bawah harus ikut diganti jika ada migrasi baru
+	migrations := Migrations()
+	assert.Equal(t, "add_user_like_product_metadata", migrations[len(migrations)-1].Name)
+	err = migrator.Redo(ctx)
+	assert.Nil(t, err)
+	for _, column := range []string{"liked_at", "rating", "source"} {
+		assert.True(t, db.Migrator().HasColumn(&UserLikeProduct{}, column))
+	}
+	assert.False(t, db.Migrator().HasColumn(&UserLikeProduct{}, "created_at"))
+	assert.True(t, db.Migrator().HasIndex(&UserLikeProduct{}, "idx_user_like_product_liked_at"))
+
+	statuses, err = migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.True(t, statuses[len(statuses)-1].Applied)
+}
+
+//semua migrasi di down lalu up lagi, drop column di sqlite membuat ulang tabel sehingga index lain harus tetap ada
+func TestMigratorDownAll(t *testing.T) {
+	db, _ := setupTestDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(db)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.Down(ctx, len(Migrations())))
+	assert.False(t, db.Migrator().HasTable(&Todo{}))
+
+	assert.Nil(t, migrator.Up(ctx))
+	assert.True(t, db.Migrator().HasIndex(&Todo{}, "idx_todos_parent_id"))
 }
 
 //hook -> function di dalam Model yang akan dipanggil sebelum melakukan operasi create/query/update/delete
 func TestHook(t *testing.T) {
+	db, _ := setupTestDB(t)
 	user := User{
 		Password: "rahasia",
 		Name: Name{
@@ -869,7 +955,7 @@ func TestHook(t *testing.T) {
 
 	err := db.Create(&user).Error
 	assert.Nil(t, err)
-	assert.NotEqual(t, "", user.ID)
+	assert.NotEqual(t, 0, user.ID)
 
 	fmt.Println(user.ID)
 }
\ No newline at end of file
@@ -0,0 +1,234 @@
+package golanggorm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"golang-gorm/fixture"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	//bcrypt cost terkecil supaya test yang membuat banyak user tetap cepat
+	PasswordCost = bcrypt.MinCost
+}
+
+// testSeed -> id dari data fixture (testdata/fixtures.yaml) yang dibuat oleh setupTestDB, dicari berdasarkan _ref
+type testSeed struct {
+	Users     map[string]int
+	Wallets   map[string]int
+	Addresses map[string]int64
+	Products  map[string]int
+	Samples   map[string]string
+}
+
+var seedSamples = []string{"Eko", "Budi", "Joko", "Rully", "Adit"}
+
+// setupTestDB -> membuat database sqlite baru di temp dir untuk satu test, lalu migrasi dan isi fixture
+func setupTestDB(t testing.TB) (*gorm.DB, *testSeed) {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	cfg.Dialect = DialectSQLite
+	cfg.DSN = filepath.Join(t.TempDir(), "golang_gorm.db")
+	cfg.MaxOpenConns = 1 //sqlite hanya boleh satu penulis dalam satu waktu
+	cfg.MaxIdleConns = 1
+	cfg.LogLevel = "silent"
+	cfg.Audit = true
+
+	db, err := Connect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("connect test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := migrateTestDB(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	seed, err := seedTestDB(db)
+	if err != nil {
+		t.Fatalf("seed test database: %v", err)
+	}
+	return db, seed
+}
+
+// setupConcurrentTestDB -> database dengan banyak koneksi untuk test concurrency. Jika TEST_DB_DSN diisi
+// (TEST_DB_DIALECT mysql atau postgres) test memakai server tersebut sehingga SELECT ... FOR UPDATE benar-benar diuji,
+// di dalamnya dibuat database/schema baru yang dihapus lagi setelah test, lihat throwawayTestDB.
+// DB_DSN milik aplikasi sengaja tidak dibaca. Tanpa TEST_DB_DSN dipakai sqlite WAL dengan beberapa koneksi,
+// sqlite tidak punya row lock sehingga transaksi dibuat IMMEDIATE (penulis bergantian, pembaca tetap jalan)
+func setupConcurrentTestDB(t testing.TB) (*gorm.DB, *testSeed) {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	cfg.Dialect = DialectSQLite
+	cfg.DSN = filepath.Join(t.TempDir(), "golang_gorm.db") + "?_journal_mode=WAL&_busy_timeout=10000&_txlock=immediate"
+	if os.Getenv("TEST_DB_DSN") != "" {
+		err := cfg.applyEnv(func(key string) string { return os.Getenv("TEST_" + key) })
+		if err != nil {
+			t.Fatalf("load test database config: %v", err)
+		}
+		cfg = throwawayTestDB(t, cfg)
+	}
+	cfg.MaxOpenConns = 10
+	cfg.MaxIdleConns = 10
+	cfg.LogLevel = "silent"
+	cfg.Audit = true
+
+	db, err := Connect(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("connect test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	//jeda setelah setiap query supaya transaksi benar-benar tumpang tindih (juga dengan satu cpu),
+	//tepat di antara SELECT ... FOR UPDATE dan UPDATE tempat lost update terjadi
+	err = db.Callback().Query().After("gorm:query").Register("test:interleave", func(*gorm.DB) {
+		time.Sleep(time.Millisecond)
+	})
+	if err != nil {
+		t.Fatalf("register test callback: %v", err)
+	}
+
+	if err := Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	seed, err := seedFixtures(db)
+	if err != nil {
+		t.Fatalf("seed test database: %v", err)
+	}
+	return db, seed
+}
+
+// throwawayTestDB -> database (mysql) atau schema (postgres) baru di server cfg, dihapus saat test selesai.
+// Hanya database yang dibuat di sini yang pernah dimigrasi atau dihapus oleh test
+func throwawayTestDB(t testing.TB, cfg Config) Config {
+	t.Helper()
+
+	admin := cfg
+	admin.MaxOpenConns, admin.MaxIdleConns = 1, 1
+	admin.LogLevel = "silent"
+	admin.Audit = false
+	db, err := Connect(context.Background(), admin)
+	if err != nil {
+		t.Fatalf("connect test database server: %v", err)
+	}
+	name := fmt.Sprintf("golang_gorm_test_%d", time.Now().UnixNano())
+
+	var create, drop string
+	switch cfg.Dialect {
+	case DialectMySQL, "":
+		dsn, err := mysqldriver.ParseDSN(cfg.DSN)
+		if err != nil {
+			t.Fatalf("parse TEST_DB_DSN: %v", err)
+		}
+		dsn.DBName = name
+		cfg.DSN = dsn.FormatDSN()
+		create, drop = "CREATE DATABASE ?", "DROP DATABASE ?"
+	case DialectPostgres, "postgresql":
+		separator := " "
+		if strings.Contains(cfg.DSN, "://") {
+			separator = "?"
+			if strings.Contains(cfg.DSN, "?") {
+				separator = "&"
+			}
+		}
+		cfg.DSN += separator + "search_path=" + name
+		create, drop = "CREATE SCHEMA ?", "DROP SCHEMA ? CASCADE"
+	default:
+		t.Fatalf("TEST_DB_DIALECT must be mysql or postgres, got %q", cfg.Dialect)
+	}
+
+	if err := db.Exec(create, clause.Table{Name: name}).Error; err != nil {
+		t.Fatalf("create test database %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if err := db.Exec(drop, clause.Table{Name: name}).Error; err != nil {
+			t.Errorf("drop test database %s: %v", name, err)
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return cfg
+}
+
+func migrateTestDB(db *gorm.DB) error {
+	if err := Migrate(context.Background(), db); err != nil {
+		return err
+	}
+	//tabel sample bukan model, dipakai oleh contoh raw sql
+	return db.Exec("create table sample (id integer primary key autoincrement, name varchar(100) not null)").Error
+}
+
+func seedTestDB(db *gorm.DB) (*testSeed, error) {
+	seed, err := seedFixtures(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range seedSamples {
+		if err := db.Exec("insert into sample(name) values (?)", name).Error; err != nil {
+			return nil, err
+		}
+	}
+	var samples []Sample
+	if err := db.Raw("select id, name from sample").Scan(&samples).Error; err != nil {
+		return nil, err
+	}
+	for _, sample := range samples {
+		seed.Samples[sample.Name] = sample.Id
+	}
+	return seed, nil
+}
+
+// seedFixtures -> isi testdata/fixtures.yaml, tanpa tabel sample yang hanya ada di sqlite
+func seedFixtures(db *gorm.DB) (*testSeed, error) {
+	ctx := context.Background()
+	seed := &testSeed{
+		Users:     map[string]int{},
+		Wallets:   map[string]int{},
+		Addresses: map[string]int64{},
+		Products:  map[string]int{},
+		Samples:   map[string]string{},
+	}
+
+	loader, err := fixture.New(db, Models()...)
+	if err != nil {
+		return nil, err
+	}
+	if err := loader.LoadFiles(ctx, filepath.Join("testdata", "fixtures.yaml")); err != nil {
+		return nil, err
+	}
+
+	for name, id := range loader.IDs("users") {
+		seed.Users[name] = id.(int)
+	}
+	for name, id := range loader.IDs("wallets") {
+		seed.Wallets[strings.TrimSuffix(name, "_wallet")] = id.(int)
+	}
+	for name, id := range loader.IDs("addresses") {
+		seed.Addresses[name] = id.(int64)
+	}
+	for name, id := range loader.IDs("products") {
+		seed.Products[name] = id.(int)
+	}
+	return seed, nil
+}
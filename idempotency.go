@@ -0,0 +1,104 @@
+package golanggorm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrIdempotencyConflict = errors.New("idempotency key reused with a different request")
+
+// IdempotencyKey -> request yang sudah pernah dijalankan beserta hasilnya
+type IdempotencyKey struct {
+	Key         string    `gorm:"primaryKey;column:idempotency_key;size:100"`
+	RequestHash string    `gorm:"column:request_hash;size:64"`
+	Response    string    `gorm:"column:response"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (i *IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+type idempotencyKeyContext struct{}
+
+// WithIdempotencyKey -> operasi yang mendukung idempotency (Transfer, Deposit, Withdraw, UserRepository.Create)
+// hanya dijalankan sekali untuk key yang sama, retry akan mendapat hasil yang sama
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+// IdempotencyKeyFrom -> key yang dipasang oleh WithIdempotencyKey
+func IdempotencyKeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContext{}).(string)
+	return key, ok && key != ""
+}
+
+func requestHash(request interface{}) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Idempotent -> menjalankan fn dalam satu transaction maksimal sekali untuk key.
+// Hasil fn (result) disimpan sebagai json, retry dengan request yang sama mengisi result dari hasil yang tersimpan
+// tanpa menjalankan fn lagi. Request berbeda dengan key yang sama -> ErrIdempotencyConflict.
+// Jika fn gagal, key tidak tersimpan sehingga boleh dicoba lagi.
+func Idempotent(ctx context.Context, db *gorm.DB, key string, request interface{}, result interface{}, fn func(tx *gorm.DB) error) error {
+	hash, err := requestHash(request)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		//insert duluan, request lain dengan key yang sama akan menunggu sampai transaction ini selesai
+		record := IdempotencyKey{Key: key, RequestHash: hash}
+		inserted := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if inserted.Error != nil {
+			return inserted.Error
+		}
+
+		if inserted.RowsAffected == 0 {
+			var existing IdempotencyKey
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&existing, "idempotency_key = ?", key).Error
+			if err != nil {
+				return err
+			}
+			if existing.RequestHash != hash {
+				return fmt.Errorf("%w: %s", ErrIdempotencyConflict, key)
+			}
+			if result == nil || existing.Response == "" {
+				return nil
+			}
+			return json.Unmarshal([]byte(existing.Response), result)
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		response, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&record).Update("response", string(response)).Error
+	})
+}
+
+// idempotentTransaction -> Idempotent jika ctx punya idempotency key, selain itu transaction biasa
+func idempotentTransaction(ctx context.Context, db *gorm.DB, request interface{}, result interface{}, fn func(tx *gorm.DB) error) error {
+	key, ok := IdempotencyKeyFrom(ctx)
+	if !ok {
+		return db.WithContext(ctx).Transaction(fn)
+	}
+	return Idempotent(ctx, db, key, request, result, fn)
+}
@@ -0,0 +1,115 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIdempotentTransfer(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := WithIdempotencyKey(context.Background(), "transfer-001")
+
+	//retry dari client dengan key yang sama hanya memindahkan uang sekali
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, service.Transfer(ctx, seed.Users["nanami"], seed.Users["gojo"], 1000))
+	}
+	assert.Equal(t, int64(1100), walletBalance(t, service, seed.Users["gojo"]))
+	assert.Equal(t, int64(999000), walletBalance(t, service, seed.Users["nanami"]))
+
+	err := service.Transfer(ctx, seed.Users["nanami"], seed.Users["gojo"], 2000)
+	assert.True(t, errors.Is(err, ErrIdempotencyConflict))
+
+	//tanpa key, setiap panggilan dijalankan
+	assert.Nil(t, service.Transfer(context.Background(), seed.Users["nanami"], seed.Users["gojo"], 1000))
+	assert.Equal(t, int64(2100), walletBalance(t, service, seed.Users["gojo"]))
+}
+
+func TestIdempotentFailureNotStored(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := WithIdempotencyKey(context.Background(), "deposit-001")
+
+	//transaction gagal, key tidak tersimpan dan boleh dicoba lagi
+	var calls int
+	err := Idempotent(ctx, db, "manual-001", "payload", nil, func(tx *gorm.DB) error {
+		calls++
+		return errors.New("gagal")
+	})
+	assert.NotNil(t, err)
+	err = Idempotent(ctx, db, "manual-001", "payload", nil, func(tx *gorm.DB) error {
+		calls++
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+
+	assert.Nil(t, service.Deposit(ctx, seed.Users["gojo"], 500, "topup-1"))
+	assert.Nil(t, service.Deposit(ctx, seed.Users["gojo"], 500, "topup-1"))
+	assert.Equal(t, int64(600), walletBalance(t, service, seed.Users["gojo"]))
+}
+
+func TestIdempotentReplayResult(t *testing.T) {
+	db, _ := setupTestDB(t)
+	ctx := context.Background()
+
+	var first, second struct{ Total int }
+	err := Idempotent(ctx, db, "hitung", 1, &first, func(tx *gorm.DB) error {
+		first.Total = 42
+		return nil
+	})
+	assert.Nil(t, err)
+	err = Idempotent(ctx, db, "hitung", 1, &second, func(tx *gorm.DB) error {
+		second.Total = 99
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 42, second.Total)
+}
+
+func TestIdempotentCreateUser(t *testing.T) {
+	ctx := WithIdempotencyKey(context.Background(), "signup-001")
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			first := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}}
+			assert.Nil(t, repo.Create(ctx, &first))
+
+			retry := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}}
+			assert.Nil(t, repo.Create(ctx, &retry))
+			assert.Equal(t, first.ID, retry.ID)
+
+			users, err := repo.FindByName(ctx, "gojo")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(users))
+
+			assert.Equal(t, first.Password, retry.Password) //hash, bukan plaintext retry
+			assert.NotEqual(t, "rahasia", retry.Password)
+
+			other := User{Password: "rahasia", Name: Name{FirstName: "Nanami"}}
+			assert.True(t, errors.Is(repo.Create(ctx, &other), ErrIdempotencyConflict))
+		})
+	}
+}
+
+func TestIdempotentCreateUserOmitsPassword(t *testing.T) {
+	db, _ := setupTestDB(t)
+	ctx := WithIdempotencyKey(context.Background(), "signup-002")
+	user := User{Password: "rahasia-sekali", Name: Name{FirstName: "Gojo"}}
+	assert.Nil(t, NewUserRepository(db).Create(ctx, &user))
+
+	//request_hash dari json tanpa password, response juga tidak berisi hash password
+	var record IdempotencyKey
+	assert.Nil(t, db.Take(&record, "idempotency_key = ?", "signup-002").Error)
+	assert.NotContains(t, record.Response, "rahasia-sekali")
+	assert.NotContains(t, record.Response, user.Password)
+	withoutPassword, err := requestHash(struct {
+		Op   string
+		User User
+	}{"create_user", User{Name: Name{FirstName: "Gojo"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, withoutPassword, record.RequestHash)
+}
@@ -0,0 +1,376 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOutOfStock           = errors.New("out of stock")
+	ErrOrderNotPending      = errors.New("order is not pending")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotActive = errors.New("reservation is not active")
+	ErrReservationExpired   = errors.New("reservation expired")
+)
+
+// status StockReservation
+const (
+	ReservationActive    = "active"    //stock sudah dikurangi, menunggu pembayaran
+	ReservationReleased  = "released"  //stock dikembalikan (dibatalkan atau expire)
+	ReservationCommitted = "committed" //order sudah dibayar
+)
+
+// StockReservation -> unit product yang ditahan untuk order pending sampai ExpiresAt
+type StockReservation struct {
+	ID        int64     `gorm:"primary_key;column:id;autoIncrement"`
+	OrderId   int       `gorm:"column:order_id;index:idx_stock_reservations_order_id"`
+	ProductId int       `gorm:"column:product_id"`
+	Quantity  int       `gorm:"column:quantity"`
+	Status    string    `gorm:"column:status;size:20;index:idx_stock_reservations_status_expires_at"`
+	ExpiresAt time.Time `gorm:"column:expires_at;index:idx_stock_reservations_status_expires_at"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Order     *Order    `gorm:"foreignKey:order_id;references:id"`   //relasi belongs to
+	Product   *Product  `gorm:"foreignKey:product_id;references:id"` //relasi belongs to
+}
+
+func (s *StockReservation) TableName() string {
+	return "stock_reservations"
+}
+
+// lockProducts -> SELECT ... FOR UPDATE product, urut id dari kecil supaya tidak deadlock
+func lockProducts(tx *gorm.DB, productIDs ...int) (map[int]*Product, error) {
+	ordered := append([]int{}, productIDs...)
+	sort.Ints(ordered)
+
+	products := make(map[int]*Product, len(ordered))
+	for _, productID := range ordered {
+		var product Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&product, "id = ?", productID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrProductNotFound, productID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		products[productID] = &product
+	}
+	return products, nil
+}
+
+// decrementStock -> stock hanya dikurangi jika cukup, kondisi di WHERE tetap menjaga
+// stock tidak minus walaupun database tidak mendukung row lock
+func decrementStock(tx *gorm.DB, productID, quantity int) error {
+	result := tx.Model(&Product{}).
+		Where("id = ? AND stock >= ?", productID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: product %d", ErrOutOfStock, productID)
+	}
+	return nil
+}
+
+func incrementStock(tx *gorm.DB, productID, quantity int) error {
+	return tx.Model(&Product{}).Where("id = ?", productID).Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+// InventoryService -> stock product dan order pending yang unit-nya di reservasi
+type InventoryService struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewInventoryService -> reservasi berlaku selama ttl sejak ditambahkan ke order
+func NewInventoryService(db *gorm.DB, ttl time.Duration) *InventoryService {
+	return &InventoryService{db: db, ttl: ttl, now: time.Now}
+}
+
+// Restock -> menambah stock product
+func (s *InventoryService) Restock(ctx context.Context, productID, quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	result := s.db.WithContext(ctx).Model(&Product{}).Where("id = ?", productID).Update("stock", gorm.Expr("stock + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrProductNotFound, productID)
+	}
+	return nil
+}
+
+// CreateOrder -> order pending kosong milik user, isi dengan Reserve
+func (s *InventoryService) CreateOrder(ctx context.Context, userID int) (*Order, error) {
+	order := &Order{UserId: userID, Status: OrderPending}
+	if err := s.db.WithContext(ctx).Create(order).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// lockPendingOrder -> SELECT ... FOR UPDATE order yang masih pending
+func lockPendingOrder(tx *gorm.DB, orderID int) (*Order, error) {
+	var order Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&order, "id = ?", orderID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != OrderPending {
+		return nil, fmt.Errorf("%w: order %d is %s", ErrOrderNotPending, orderID, order.Status)
+	}
+	return &order, nil
+}
+
+// refreshOrderTotals -> item_count dan total dihitung ulang dari order_details
+func refreshOrderTotals(tx *gorm.DB, order *Order) error {
+	var totals struct {
+		ItemCount int
+		Total     int64
+	}
+	err := tx.Model(&OrderDetail{}).
+		Select("COALESCE(SUM(quantity), 0) AS item_count, COALESCE(SUM(subtotal), 0) AS total").
+		Where("order_id = ?", order.ID).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+	order.ItemCount, order.Total = totals.ItemCount, totals.Total
+	return tx.Model(order).Select("item_count", "total").Updates(order).Error
+}
+
+// Reserve -> menambah product ke order pending, stock langsung dikurangi dan ditahan sampai ExpiresAt
+func (s *InventoryService) Reserve(ctx context.Context, orderID, productID, quantity int) (*StockReservation, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: product %d", ErrInvalidQuantity, productID)
+	}
+	var reservation *StockReservation
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPendingOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		products, err := lockProducts(tx, productID)
+		if err != nil {
+			return err
+		}
+		if err := decrementStock(tx, productID, quantity); err != nil {
+			return err
+		}
+
+		reservation = &StockReservation{
+			OrderId:   orderID,
+			ProductId: productID,
+			Quantity:  quantity,
+			Status:    ReservationActive,
+			ExpiresAt: s.now().Add(s.ttl),
+		}
+		if err := tx.Create(reservation).Error; err != nil {
+			return err
+		}
+
+		//product yang sama digabung ke satu detail, harga tetap harga saat pertama kali ditambahkan
+		var detail OrderDetail
+		err = tx.Where("order_id = ? AND product_id = ?", orderID, productID).Take(&detail).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			detail = OrderDetail{OrderId: orderID, ProductId: productID, UnitPrice: products[productID].Price}
+		case err != nil:
+			return err
+		}
+		detail.Quantity += quantity
+		detail.Subtotal = detail.UnitPrice * int64(detail.Quantity)
+		if err := tx.Save(&detail).Error; err != nil {
+			return err
+		}
+		return refreshOrderTotals(tx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// releaseReservation -> stock dikembalikan dan quantity dikurangi dari detail order
+func releaseReservation(tx *gorm.DB, reservation *StockReservation) error {
+	result := tx.Model(reservation).Where("status = ?", ReservationActive).Update("status", ReservationReleased)
+	if result.Error != nil {
+		return result.Error
+	}
+	//sudah di release oleh request lain
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrReservationNotActive, reservation.ID)
+	}
+	if err := incrementStock(tx, reservation.ProductId, reservation.Quantity); err != nil {
+		return err
+	}
+
+	var detail OrderDetail
+	if err := tx.Where("order_id = ? AND product_id = ?", reservation.OrderId, reservation.ProductId).Take(&detail).Error; err != nil {
+		return err
+	}
+	detail.Quantity -= reservation.Quantity
+	if detail.Quantity <= 0 {
+		return tx.Delete(&detail).Error
+	}
+	detail.Subtotal = detail.UnitPrice * int64(detail.Quantity)
+	return tx.Save(&detail).Error
+}
+
+// Release -> membatalkan satu reservasi dari order pending, stock dikembalikan
+func (s *InventoryService) Release(ctx context.Context, reservationID int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reservation StockReservation
+		err := tx.Take(&reservation, "id = ?", reservationID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReservationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		order, err := lockPendingOrder(tx, reservation.OrderId)
+		if err != nil {
+			return err
+		}
+		if err := releaseReservation(tx, &reservation); err != nil {
+			return err
+		}
+		return refreshOrderTotals(tx, order)
+	})
+}
+
+// closeOrder -> semua reservasi aktif order di release lalu status order diganti
+func closeOrder(tx *gorm.DB, order *Order, status string) error {
+	var reservations []StockReservation
+	if err := tx.Where("order_id = ? AND status = ?", order.ID, ReservationActive).Order("id").Find(&reservations).Error; err != nil {
+		return err
+	}
+	for i := range reservations {
+		if err := releaseReservation(tx, &reservations[i]); err != nil {
+			return err
+		}
+	}
+	if err := refreshOrderTotals(tx, order); err != nil {
+		return err
+	}
+	order.Status = status
+	return tx.Model(order).Update("status", status).Error
+}
+
+// CancelOrder -> order pending dibatalkan, semua stock yang di reservasi dikembalikan
+func (s *InventoryService) CancelOrder(ctx context.Context, orderID int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPendingOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		return closeOrder(tx, order, OrderCancelled)
+	})
+}
+
+// ExpireReservations -> order pending yang punya reservasi lewat ExpiresAt menjadi expired dan stock-nya
+// dikembalikan, dijalankan berkala. Mengembalikan jumlah order yang expire.
+func (s *InventoryService) ExpireReservations(ctx context.Context) (int64, error) {
+	var orderIDs []int
+	err := s.db.WithContext(ctx).Model(&StockReservation{}).
+		Distinct("order_id").
+		Where("status = ? AND expires_at <= ?", ReservationActive, s.now()).
+		Order("order_id").
+		Pluck("order_id", &orderIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for _, orderID := range orderIDs {
+		//setiap order transaction sendiri, order yang sudah dibayar/dibatalkan di antaranya dilewati
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			order, err := lockPendingOrder(tx, orderID)
+			if err != nil {
+				return err
+			}
+			return closeOrder(tx, order, OrderExpired)
+		})
+		if errors.Is(err, ErrOrderNotPending) {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// PayOrder -> order pending dibayar dari wallet pemiliknya, reservasi menjadi committed
+func (s *InventoryService) PayOrder(ctx context.Context, orderID int) (*Order, error) {
+	var order *Order
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = lockPendingOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.ItemCount == 0 {
+			return ErrEmptyCart
+		}
+		//reservasi yang sudah lewat waktu tidak boleh dibayar, tunggu ExpireReservations
+		var expired int64
+		err = tx.Model(&StockReservation{}).
+			Where("order_id = ? AND status = ? AND expires_at <= ?", orderID, ReservationActive, s.now()).
+			Count(&expired).Error
+		if err != nil {
+			return err
+		}
+		if expired > 0 {
+			return fmt.Errorf("%w: order %d", ErrReservationExpired, orderID)
+		}
+
+		wallets, err := lockWallets(tx, order.UserId)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[order.UserId]
+		if wallet.Balance < order.Total {
+			return fmt.Errorf("%w: balance %d, total %d", ErrInsufficientFunds, wallet.Balance, order.Total)
+		}
+
+		if order.LedgerReference, err = newReference("order"); err != nil {
+			return err
+		}
+		if order.Total > 0 {
+			err := postLedger(tx, order.LedgerReference,
+				LedgerEntry{WalletId: wallet.ID, Amount: order.Total, Direction: LedgerDebit},
+				LedgerEntry{WalletId: SystemWalletID, Amount: order.Total, Direction: LedgerCredit},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		err = tx.Model(&StockReservation{}).
+			Where("order_id = ? AND status = ?", orderID, ReservationActive).
+			Update("status", ReservationCommitted).Error
+		if err != nil {
+			return err
+		}
+		order.Status = OrderPaid
+		return tx.Model(order).Select("status", "ledger_reference").Updates(order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
@@ -0,0 +1,277 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func productStock(t *testing.T, db *gorm.DB, productID int) int {
+	var product Product
+	assert.Nil(t, db.Take(&product, "id = ?", productID).Error)
+	return product.Stock
+}
+
+func TestReserveAndPay(t *testing.T) {
+	db, seed := setupTestDB(t)
+	inventory := NewInventoryService(db, 15*time.Minute)
+	ctx := context.Background()
+	product := seed.Products["product_3"] //stock 20, harga 75000
+
+	order, err := inventory.CreateOrder(ctx, seed.Users["kento"])
+	assert.Nil(t, err)
+	assert.Equal(t, OrderPending, order.Status)
+
+	_, err = inventory.Reserve(ctx, order.ID, product, 2)
+	assert.Nil(t, err)
+	second, err := inventory.Reserve(ctx, order.ID, product, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 15, productStock(t, db, product))
+
+	_, err = inventory.Reserve(ctx, order.ID, product, 16)
+	assert.True(t, errors.Is(err, ErrOutOfStock))
+	assert.Equal(t, 15, productStock(t, db, product))
+
+	assert.Nil(t, inventory.Release(ctx, second.ID))
+	assert.True(t, errors.Is(inventory.Release(ctx, second.ID), ErrReservationNotActive))
+	assert.Equal(t, 18, productStock(t, db, product))
+
+	paid, err := inventory.PayOrder(ctx, order.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, OrderPaid, paid.Status)
+	assert.Equal(t, 2, paid.ItemCount)
+	assert.Equal(t, int64(150000), paid.Total)
+	assert.Equal(t, int64(1000000-150000), walletBalance(t, NewWalletService(db), seed.Users["kento"]))
+
+	loaded, err := NewOrderService(db).GetOrder(ctx, order.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(loaded.Details))
+	assert.Equal(t, 2, loaded.Details[0].Quantity)
+
+	var committed int64
+	assert.Nil(t, db.Model(&StockReservation{}).Where("order_id = ? AND status = ?", order.ID, ReservationCommitted).Count(&committed).Error)
+	assert.Equal(t, int64(1), committed)
+
+	//order yang sudah dibayar tidak bisa diubah lagi
+	_, err = inventory.Reserve(ctx, order.ID, product, 1)
+	assert.True(t, errors.Is(err, ErrOrderNotPending))
+	assert.True(t, errors.Is(inventory.CancelOrder(ctx, order.ID), ErrOrderNotPending))
+	assert.Equal(t, 18, productStock(t, db, product))
+}
+
+func TestCancelAndExpireReservations(t *testing.T) {
+	db, seed := setupTestDB(t)
+	now := time.Now()
+	inventory := &InventoryService{db: db, ttl: time.Minute, now: func() time.Time { return now }}
+	ctx := context.Background()
+	product1, product2 := seed.Products["product_1"], seed.Products["product_2"] //stock 5 dan 10
+
+	cancelled, err := inventory.CreateOrder(ctx, seed.Users["toji"])
+	assert.Nil(t, err)
+	_, err = inventory.Reserve(ctx, cancelled.ID, product1, 2)
+	assert.Nil(t, err)
+	assert.Nil(t, inventory.CancelOrder(ctx, cancelled.ID))
+	assert.Equal(t, 5, productStock(t, db, product1))
+
+	stale, err := inventory.CreateOrder(ctx, seed.Users["toji"])
+	assert.Nil(t, err)
+	_, err = inventory.Reserve(ctx, stale.ID, product1, 1)
+	assert.Nil(t, err)
+	_, err = inventory.Reserve(ctx, stale.ID, product2, 4)
+	assert.Nil(t, err)
+
+	now = now.Add(30 * time.Second)
+	fresh, err := inventory.CreateOrder(ctx, seed.Users["nanami"])
+	assert.Nil(t, err)
+	_, err = inventory.Reserve(ctx, fresh.ID, product2, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, productStock(t, db, product2))
+
+	//reservasi order stale lewat satu menit, order fresh belum
+	now = now.Add(45 * time.Second)
+	_, err = inventory.PayOrder(ctx, stale.ID)
+	assert.True(t, errors.Is(err, ErrReservationExpired))
+
+	expired, err := inventory.ExpireReservations(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), expired)
+	assert.Equal(t, 5, productStock(t, db, product1))
+	assert.Equal(t, 9, productStock(t, db, product2))
+
+	var order Order
+	assert.Nil(t, db.Take(&order, "id = ?", stale.ID).Error)
+	assert.Equal(t, OrderExpired, order.Status)
+	assert.Equal(t, int64(0), order.Total)
+
+	expired, err = inventory.ExpireReservations(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), expired)
+
+	_, err = inventory.PayOrder(ctx, fresh.ID)
+	assert.Nil(t, err)
+}
+
+func TestCheckoutDecrementsStock(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewOrderService(db)
+	ctx := context.Background()
+
+	_, err := service.Checkout(ctx, seed.Users["nanami"], []CheckoutItem{{ProductID: seed.Products["product_2"], Quantity: 3}})
+	assert.Nil(t, err)
+	assert.Equal(t, 7, productStock(t, db, seed.Products["product_2"]))
+
+	//stock tidak cukup, tidak ada yang berubah termasuk product lain di order yang sama
+	_, err = service.Checkout(ctx, seed.Users["nanami"], []CheckoutItem{
+		{ProductID: seed.Products["product_3"], Quantity: 1},
+		{ProductID: seed.Products["product_2"], Quantity: 8},
+	})
+	assert.True(t, errors.Is(err, ErrOutOfStock))
+	assert.Equal(t, 7, productStock(t, db, seed.Products["product_2"]))
+	assert.Equal(t, 20, productStock(t, db, seed.Products["product_3"]))
+}
+
+// buyers -> user yang punya wallet dan cukup saldo untuk product seharga 1
+func buyers(seed *testSeed) []int {
+	return []int{seed.Users["gojo"], seed.Users["nanami"], seed.Users["laksa"], seed.Users["kento"], seed.Users["toji"], seed.Users["user_a"]}
+}
+
+func TestCheckoutLastUnitConcurrent(t *testing.T) {
+	db, seed := setupConcurrentTestDB(t)
+	service := NewOrderService(db)
+	ctx := context.Background()
+
+	product := Product{Name: "Edisi Terbatas", Price: 1, Stock: 1}
+	assert.Nil(t, db.Create(&product).Error)
+
+	users := buyers(seed)
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		sold, lost int
+	)
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.Checkout(ctx, users[i%len(users)], []CheckoutItem{{ProductID: product.ID, Quantity: 1}})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				sold++
+			case errors.Is(err, ErrOutOfStock):
+				lost++
+			default:
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	assert.Greater(t, sqlDB.Stats().OpenConnections, 1) //checkout benar-benar berjalan di beberapa koneksi
+
+	assert.Equal(t, 1, sold)
+	assert.Equal(t, 29, lost)
+	assert.Equal(t, 0, productStock(t, db, product.ID))
+
+	var details int64
+	assert.Nil(t, db.Model(&OrderDetail{}).Where("product_id = ?", product.ID).Count(&details).Error)
+	assert.Equal(t, int64(1), details)
+}
+
+func TestReserveLastUnitConcurrent(t *testing.T) {
+	db, seed := setupConcurrentTestDB(t)
+	inventory := NewInventoryService(db, time.Minute)
+	ctx := context.Background()
+
+	product := Product{Name: "Edisi Terbatas", Price: 1, Stock: 1}
+	assert.Nil(t, db.Create(&product).Error)
+
+	var orders []*Order
+	for _, userID := range buyers(seed) {
+		for i := 0; i < 3; i++ {
+			order, err := inventory.CreateOrder(ctx, userID)
+			assert.Nil(t, err)
+			orders = append(orders, order)
+		}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved []int
+	)
+	for _, order := range orders {
+		wg.Add(1)
+		go func(order *Order) {
+			defer wg.Done()
+			_, err := inventory.Reserve(ctx, order.ID, product.ID, 1)
+			if err != nil && !errors.Is(err, ErrOutOfStock) {
+				t.Error(err)
+			}
+			if err == nil {
+				mu.Lock()
+				reserved = append(reserved, order.ID)
+				mu.Unlock()
+			}
+		}(order)
+	}
+	wg.Wait()
+
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	assert.Greater(t, sqlDB.Stats().OpenConnections, 1) //reservasi benar-benar berjalan di beberapa koneksi
+
+	assert.Equal(t, 1, len(reserved))
+	assert.Equal(t, 0, productStock(t, db, product.ID))
+
+	//setelah dibatalkan, unit terakhir bisa di reservasi order lain
+	assert.Nil(t, inventory.CancelOrder(ctx, reserved[0]))
+	assert.Equal(t, 1, productStock(t, db, product.ID))
+	for _, order := range orders {
+		if order.ID != reserved[0] {
+			_, err := inventory.Reserve(ctx, order.ID, product.ID, 1)
+			assert.Nil(t, err)
+			break
+		}
+	}
+	assert.Equal(t, 0, productStock(t, db, product.ID))
+}
+
+func TestLegacyProductNeedsRestock(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	assert.Nil(t, err)
+
+	//kembali ke sebelum add_product_stock_and_reservations, lalu ada product yang dibuat tanpa stock
+	migrations := Migrations()
+	for i, migration := range migrations {
+		if migration.Name == "add_product_stock_and_reservations" {
+			assert.Nil(t, migrator.Down(ctx, len(migrations)-i))
+		}
+	}
+	assert.Nil(t, db.Exec("insert into products (name, price) values (?, ?)", "Product Lama", 5000).Error)
+	assert.Nil(t, migrator.Up(ctx))
+
+	//stock lama tidak diketahui, product tidak bisa dijual sampai di restock
+	var product Product
+	assert.Nil(t, db.Take(&product, "name = ?", "Product Lama").Error)
+	assert.Equal(t, 0, product.Stock)
+	service := NewOrderService(db)
+	items := []CheckoutItem{{ProductID: product.ID, Quantity: 1}}
+	_, err = service.Checkout(ctx, seed.Users["nanami"], items)
+	assert.True(t, errors.Is(err, ErrOutOfStock))
+
+	assert.Nil(t, NewInventoryService(db, time.Minute).Restock(ctx, product.ID, 3))
+	_, err = service.Checkout(ctx, seed.Users["nanami"], items)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, productStock(t, db, product.ID))
+}
@@ -0,0 +1,213 @@
+package golanggorm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	LedgerCredit = "credit" // uang masuk ke wallet
+	LedgerDebit  = "debit"  // uang keluar dari wallet
+)
+
+// SystemWalletID -> lawan transaksi untuk uang yang masuk/keluar dari luar sistem (deposit, withdraw, saldo awal)
+const SystemWalletID = 0
+
+var ErrUnbalancedLedger = errors.New("ledger entries are not balanced")
+
+// LedgerEntry -> satu baris mutasi, Wallet.Balance hanyalah cache dari total ledger
+type LedgerEntry struct {
+	ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`
+	WalletId  int       `gorm:"column:wallet_id;index"`
+	Amount    int64     `gorm:"column:amount"`
+	Direction string    `gorm:"column:direction;size:10"`
+	Reference string    `gorm:"column:reference;size:100;index"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (l *LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// signed -> amount positif untuk credit, negatif untuk debit
+func (l LedgerEntry) signed() int64 {
+	if l.Direction == LedgerDebit {
+		return -l.Amount
+	}
+	return l.Amount
+}
+
+// Statement -> mutasi wallet dalam rentang waktu [From, To)
+type Statement struct {
+	WalletID       int
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Entries        []LedgerEntry
+}
+
+// newReference -> reference ledger acak, error jika crypto/rand gagal supaya tidak menghasilkan reference yang sama
+func newReference(prefix string) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate %s reference: %w", prefix, err)
+	}
+	return prefix + "-" + hex.EncodeToString(buf), nil
+}
+
+// recordLedger -> menyimpan entries dengan reference yang sama, total debit harus sama dengan total credit
+func recordLedger(tx *gorm.DB, reference string, entries ...LedgerEntry) error {
+	var total int64
+	for i := range entries {
+		if entries[i].Amount <= 0 {
+			return ErrInvalidAmount
+		}
+		if entries[i].Direction != LedgerCredit && entries[i].Direction != LedgerDebit {
+			return fmt.Errorf("ledger: unknown direction %q", entries[i].Direction)
+		}
+		entries[i].Reference = reference
+		total += entries[i].signed()
+	}
+	if total != 0 {
+		return fmt.Errorf("%w: reference %s off by %d", ErrUnbalancedLedger, reference, total)
+	}
+	return tx.Create(&entries).Error
+}
+
+// postLedger -> recordLedger lalu mengubah cache balance wallet yang terlibat
+func postLedger(tx *gorm.DB, reference string, entries ...LedgerEntry) error {
+	if err := recordLedger(tx, reference, entries...); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.WalletId == SystemWalletID {
+			continue
+		}
+		err := tx.Model(&Wallet{}).Where("id = ?", entry.WalletId).Update("balance", gorm.Expr("balance + ?", entry.signed())).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deposit -> menambah saldo wallet userID dari luar sistem
+func (s *WalletService) Deposit(ctx context.Context, userID int, amount int64, reference string) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	request := struct {
+		Op        string
+		User      int
+		Amount    int64
+		Reference string
+	}{"deposit", userID, amount, reference}
+	return idempotentTransaction(ctx, s.db, request, nil, func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, userID)
+		if err != nil {
+			return err
+		}
+		return postLedger(tx, reference,
+			LedgerEntry{WalletId: SystemWalletID, Amount: amount, Direction: LedgerDebit},
+			LedgerEntry{WalletId: wallets[userID].ID, Amount: amount, Direction: LedgerCredit},
+		)
+	})
+}
+
+// Withdraw -> mengurangi saldo wallet userID keluar dari sistem
+func (s *WalletService) Withdraw(ctx context.Context, userID int, amount int64, reference string) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	request := struct {
+		Op        string
+		User      int
+		Amount    int64
+		Reference string
+	}{"withdraw", userID, amount, reference}
+	return idempotentTransaction(ctx, s.db, request, nil, func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, userID)
+		if err != nil {
+			return err
+		}
+		if wallets[userID].Balance < amount {
+			return fmt.Errorf("%w: balance %d, amount %d", ErrInsufficientFunds, wallets[userID].Balance, amount)
+		}
+		return postLedger(tx, reference,
+			LedgerEntry{WalletId: wallets[userID].ID, Amount: amount, Direction: LedgerDebit},
+			LedgerEntry{WalletId: SystemWalletID, Amount: amount, Direction: LedgerCredit},
+		)
+	})
+}
+
+// ledgerSum -> total ledger wallet (credit - debit) sesuai kondisi tambahan
+func ledgerSum(db *gorm.DB, walletID int, query string, args ...interface{}) (int64, error) {
+	var total int64
+	db = db.Model(&LedgerEntry{}).
+		Select("COALESCE(SUM(CASE WHEN direction = ? THEN -amount ELSE amount END), 0)", LedgerDebit).
+		Where("wallet_id = ?", walletID)
+	if query != "" {
+		db = db.Where(query, args...)
+	}
+	err := db.Scan(&total).Error
+	return total, err
+}
+
+// RebuildBalance -> menghitung ulang Wallet.Balance dari ledger, mengembalikan saldo yang baru
+func (s *WalletService) RebuildBalance(ctx context.Context, walletID int) (int64, error) {
+	var balance int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&wallet, "id = ?", walletID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: id %d", ErrWalletNotFound, walletID)
+		}
+		if err != nil {
+			return err
+		}
+
+		balance, err = ledgerSum(tx, walletID, "")
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Wallet{}).Where("id = ?", walletID).Update("balance", balance).Error
+	})
+	return balance, err
+}
+
+// Statement -> saldo awal, mutasi dan saldo akhir wallet dalam rentang [from, to)
+func (s *WalletService) Statement(ctx context.Context, walletID int, from, to time.Time) (*Statement, error) {
+	db := s.db.WithContext(ctx)
+
+	var count int64
+	if err := db.Model(&Wallet{}).Where("id = ?", walletID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: id %d", ErrWalletNotFound, walletID)
+	}
+
+	opening, err := ledgerSum(db, walletID, "created_at < ?", from)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{WalletID: walletID, From: from, To: to, OpeningBalance: opening, ClosingBalance: opening}
+	err = db.Where("wallet_id = ? AND created_at >= ? AND created_at < ?", walletID, from, to).
+		Order("created_at").Order("id").Find(&statement.Entries).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range statement.Entries {
+		statement.ClosingBalance += entry.signed()
+	}
+	return statement, nil
+}
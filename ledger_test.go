@@ -0,0 +1,87 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerBalanced(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	assert.Nil(t, service.Transfer(ctx, seed.Users["nanami"], seed.Users["gojo"], 400000))
+	assert.Nil(t, service.Deposit(ctx, seed.Users["gojo"], 50, "topup-1"))
+	assert.Nil(t, service.Withdraw(ctx, seed.Users["laksa"], 1000, "tarik-1"))
+	err := service.Withdraw(ctx, seed.Users["gojo"], 1000000, "tarik-2")
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+
+	//total semua entry (termasuk system wallet) selalu nol
+	var total int64
+	err = db.Model(&LedgerEntry{}).Select("COALESCE(SUM(CASE WHEN direction = 'debit' THEN -amount ELSE amount END), 0)").Scan(&total).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+
+	//balance yang di cache sama dengan hasil hitung ulang dari ledger
+	var wallets []Wallet
+	assert.Nil(t, db.Find(&wallets).Error)
+	for _, wallet := range wallets {
+		rebuilt, err := service.RebuildBalance(ctx, wallet.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, wallet.Balance, rebuilt, "wallet %d", wallet.ID)
+	}
+}
+
+func TestRebuildBalance(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	//cache rusak, ledger tetap benar
+	walletID := seed.Wallets["gojo"]
+	assert.Nil(t, db.Model(&Wallet{}).Where("id = ?", walletID).Update("balance", 999).Error)
+
+	balance, err := service.RebuildBalance(ctx, walletID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), balance)
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", walletID).Error)
+	assert.Equal(t, int64(100), wallet.Balance)
+
+	_, err = service.RebuildBalance(ctx, -1)
+	assert.True(t, errors.Is(err, ErrWalletNotFound))
+}
+
+func TestStatement(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	start := time.Now()
+	assert.Nil(t, service.Transfer(ctx, seed.Users["nanami"], seed.Users["gojo"], 400000))
+	assert.Nil(t, service.Transfer(ctx, seed.Users["gojo"], seed.Users["nanami"], 100))
+	end := time.Now().Add(time.Minute)
+
+	statement, err := service.Statement(ctx, seed.Wallets["nanami"], start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000), statement.OpeningBalance)
+	assert.Equal(t, int64(600100), statement.ClosingBalance)
+	assert.Equal(t, 2, len(statement.Entries))
+	assert.Equal(t, LedgerDebit, statement.Entries[0].Direction)
+	assert.Equal(t, LedgerCredit, statement.Entries[1].Direction)
+
+	//dari awal, saldo awal fixture ikut tercatat
+	statement, err = service.Statement(ctx, seed.Wallets["nanami"], time.Time{}, end)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), statement.OpeningBalance)
+	assert.Equal(t, 3, len(statement.Entries))
+	assert.Equal(t, int64(600100), statement.ClosingBalance)
+
+	_, err = service.Statement(ctx, -1, start, end)
+	assert.True(t, errors.Is(err, ErrWalletNotFound))
+}
@@ -0,0 +1,242 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserLikeProduct -> baris tabel many2many user_like_product, didaftarkan lewat SetupJoinTable.
+// Association("LikeProducts").Append tetap bisa dipakai, metadata diisi hook dari WithLikeMetadata
+type UserLikeProduct struct {
+	UserId    int        `gorm:"primaryKey;column:user_id;autoIncrement:false"`
+	ProductId int        `gorm:"primaryKey;column:product_id;autoIncrement:false;index:idx_user_like_product_product_id"`
+	LikedAt   *time.Time `gorm:"column:liked_at;index:idx_user_like_product_liked_at"` //nil untuk like yang dibuat tanpa model ini
+	Rating    *int       `gorm:"column:rating"`                                        //1 sampai 5, nil jika user hanya like
+	Source    string     `gorm:"column:source;size:50;not null;default:direct"`        //asal like, misal web atau mobile
+	User      *User      `gorm:"foreignKey:user_id;references:id"`                     //relasi belongs to
+	Product   *Product   `gorm:"foreignKey:product_id;references:id"`                  //relasi belongs to
+}
+
+func (u *UserLikeProduct) TableName() string {
+	return "user_like_product"
+}
+
+var ErrInvalidRating = errors.New("rating must be between 1 and 5")
+
+// LikeSourceDirect -> source like yang dibuat tanpa WithLikeMetadata
+const LikeSourceDirect = "direct"
+
+// LikeMetadata -> metadata like yang tidak bisa dikirim lewat Association().Append
+type LikeMetadata struct {
+	Rating *int
+	Source string
+}
+
+type likeMetadataContext struct{}
+
+// WithLikeMetadata -> like yang dibuat dengan ctx ini (termasuk lewat Association) ikut menyimpan metadata
+func WithLikeMetadata(ctx context.Context, metadata LikeMetadata) context.Context {
+	return context.WithValue(ctx, likeMetadataContext{}, metadata)
+}
+
+func (u *UserLikeProduct) BeforeCreate(tx *gorm.DB) error {
+	if metadata, ok := tx.Statement.Context.Value(likeMetadataContext{}).(LikeMetadata); ok {
+		if u.Rating == nil {
+			u.Rating = metadata.Rating
+		}
+		if u.Source == "" {
+			u.Source = metadata.Source
+		}
+	}
+	if u.Source == "" {
+		u.Source = LikeSourceDirect
+	}
+	if u.LikedAt == nil {
+		now := tx.Statement.DB.NowFunc()
+		u.LikedAt = &now
+	}
+	//rating dari metadata baru terisi di sini, jadi validasi dilakukan setelahnya
+	return u.validate()
+}
+
+func (u *UserLikeProduct) BeforeUpdate(tx *gorm.DB) error {
+	return u.validate()
+}
+
+func (u *UserLikeProduct) validate() error {
+	if u.Rating != nil && (*u.Rating < 1 || *u.Rating > 5) {
+		return fmt.Errorf("%w: %d", ErrInvalidRating, *u.Rating)
+	}
+	return nil
+}
+
+// setupJoinTables -> join model custom untuk relasi many2many, dipanggil sekali di Connect
+func setupJoinTables(db *gorm.DB) error {
+	if err := db.SetupJoinTable(&User{}, "LikeProducts", &UserLikeProduct{}); err != nil {
+		return err
+	}
+	return db.SetupJoinTable(&Product{}, "LikedByUsers", &UserLikeProduct{})
+}
+
+// ProductLikes -> product beserta jumlah like
+type ProductLikes struct {
+	Product Product
+	Likes   int64
+}
+
+// PopularityService -> ranking product berdasarkan like, dihitung dengan agregasi di database
+type PopularityService struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewPopularityService(db *gorm.DB) *PopularityService {
+	return &PopularityService{db: db, now: time.Now}
+}
+
+// rankLikes -> jumlah like per column product, terbanyak lebih dulu lalu product id, kemudian product-nya dimuat
+func rankLikes(db *gorm.DB, query *gorm.DB, column string, page Page) ([]ProductLikes, error) {
+	var counts []struct {
+		ProductId int
+		Likes     int64
+	}
+	err := query.Select(column + " AS product_id, COUNT(*) AS likes").
+		Group(column).Order("likes DESC").Order(column).
+		Limit(page.limit()).Offset(page.offset()).
+		Scan(&counts).Error
+	if err != nil || len(counts) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int, len(counts))
+	for i, count := range counts {
+		ids[i] = count.ProductId
+	}
+	var products []Product
+	if err := db.Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	ranking := make([]ProductLikes, len(counts))
+	for i, count := range counts {
+		ranking[i] = ProductLikes{Product: byID[count.ProductId], Likes: count.Likes}
+	}
+	return ranking, nil
+}
+
+// TopLiked -> product dengan like terbanyak sepanjang waktu
+func (s *PopularityService) TopLiked(ctx context.Context, page Page) ([]ProductLikes, error) {
+	db := s.db.WithContext(ctx)
+	return rankLikes(db, db.Model(&UserLikeProduct{}), "product_id", page)
+}
+
+// Trending -> product dengan like terbanyak dalam window terakhir
+func (s *PopularityService) Trending(ctx context.Context, window time.Duration, page Page) ([]ProductLikes, error) {
+	db := s.db.WithContext(ctx)
+	query := db.Model(&UserLikeProduct{}).Where("liked_at >= ?", s.now().Add(-window))
+	return rankLikes(db, query, "product_id", page)
+}
+
+// AlsoLiked -> product lain yang disukai user yang juga menyukai productID, Likes berisi jumlah user yang sama
+func (s *PopularityService) AlsoLiked(ctx context.Context, productID int, page Page) ([]ProductLikes, error) {
+	db := s.db.WithContext(ctx)
+	query := db.Table("user_like_product AS other").
+		Joins("JOIN user_like_product AS liked ON liked.user_id = other.user_id AND liked.product_id = ?", productID).
+		Where("other.product_id <> ?", productID)
+	return rankLikes(db, query, "other.product_id", page)
+}
+
+// LikeService -> like beserta metadata-nya
+type LikeService struct {
+	db *gorm.DB
+}
+
+func NewLikeService(db *gorm.DB) *LikeService {
+	return &LikeService{db: db}
+}
+
+// Like -> user menyukai product, like yang sudah ada hanya diperbarui source dan rating yang diisi (liked_at tetap)
+func (s *LikeService) Like(ctx context.Context, userID, productID int, metadata LikeMetadata) (*UserLikeProduct, error) {
+	like := &UserLikeProduct{UserId: userID, ProductId: productID, Rating: metadata.Rating, Source: metadata.Source}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: %d", ErrProductNotFound, productID)
+		}
+		//like ulang tanpa rating atau source tidak menimpa nilai yang sudah ada (source default hanya untuk like baru)
+		var columns []string
+		if metadata.Source != "" {
+			columns = append(columns, "source")
+		}
+		if metadata.Rating != nil {
+			columns = append(columns, "rating")
+		}
+		conflict := clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}}}
+		if len(columns) == 0 {
+			conflict.DoNothing = true
+		} else {
+			conflict.DoUpdates = clause.AssignmentColumns(columns)
+		}
+		err := tx.Clauses(conflict).Create(like).Error
+		if err != nil {
+			return err
+		}
+		return tx.Take(like, "user_id = ? AND product_id = ?", userID, productID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return like, nil
+}
+
+// Unlike -> menghapus like, tidak error jika user memang belum menyukai product
+func (s *LikeService) Unlike(ctx context.Context, userID, productID int) error {
+	return s.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).Delete(&UserLikeProduct{}).Error
+}
+
+// ListByUser -> like milik user beserta product-nya, terbaru lebih dulu
+func (s *LikeService) ListByUser(ctx context.Context, userID int, page Page) ([]UserLikeProduct, error) {
+	var likes []UserLikeProduct
+	err := s.db.WithContext(ctx).Preload("Product").
+		Where("user_id = ?", userID).
+		Order("liked_at DESC").Order("product_id").
+		Limit(page.limit()).Offset(page.offset()).
+		Find(&likes).Error
+	return likes, err
+}
+
+// ListByProduct -> like untuk product beserta user-nya, terbaru lebih dulu
+func (s *LikeService) ListByProduct(ctx context.Context, productID int, page Page) ([]UserLikeProduct, error) {
+	var likes []UserLikeProduct
+	err := s.db.WithContext(ctx).Preload("User").
+		Where("product_id = ?", productID).
+		Order("liked_at DESC").Order("user_id").
+		Limit(page.limit()).Offset(page.offset()).
+		Find(&likes).Error
+	return likes, err
+}
+
+// AverageRating -> rata-rata rating product dan jumlah like yang memberi rating
+func (s *LikeService) AverageRating(ctx context.Context, productID int) (float64, int64, error) {
+	var result struct {
+		Average float64
+		Count   int64
+	}
+	err := s.db.WithContext(ctx).Model(&UserLikeProduct{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(rating) AS count").
+		Where("product_id = ?", productID).
+		Scan(&result).Error
+	return result.Average, result.Count, err
+}
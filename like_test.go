@@ -0,0 +1,191 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// likeProducts -> user menyukai product lewat Association, seperti aplikasi biasa
+func likeProducts(t *testing.T, db *gorm.DB, userID int, productIDs ...int) {
+	var products []Product
+	assert.Nil(t, db.Find(&products, productIDs).Error)
+	assert.Nil(t, db.Model(&User{ID: userID}).Association("LikeProducts").Append(products))
+}
+
+func rankedProducts(ranking []ProductLikes) map[string]int64 {
+	likes := map[string]int64{}
+	for _, rank := range ranking {
+		likes[rank.Product.Name] = rank.Likes
+	}
+	return likes
+}
+
+func TestLikeAppendSetsLikedAt(t *testing.T) {
+	db, seed := setupTestDB(t)
+	before := time.Now().Add(-time.Second)
+	likeProducts(t, db, seed.Users["nanami"], seed.Products["product_2"])
+
+	var like UserLikeProduct
+	err := db.Take(&like, "user_id = ? AND product_id = ?", seed.Users["nanami"], seed.Products["product_2"]).Error
+	assert.Nil(t, err)
+	assert.True(t, like.LikedAt.After(before))
+}
+
+func TestTopLikedAndAlsoLiked(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewPopularityService(db)
+	ctx := context.Background()
+	product1, product2, product3 := seed.Products["product_1"], seed.Products["product_2"], seed.Products["product_3"]
+
+	//fixture: kento menyukai product_3, user_c menyukai product_1
+	likeProducts(t, db, seed.Users["nanami"], product1, product2)
+	likeProducts(t, db, seed.Users["laksa"], product1, product2, product3)
+	likeProducts(t, db, seed.Users["toji"], product2)
+
+	top, err := service.TopLiked(ctx, Page{Size: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(top))
+	assert.Equal(t, product1, top[0].Product.ID) //jumlah sama, id lebih kecil lebih dulu
+	assert.Equal(t, int64(3), top[0].Likes)
+	assert.Equal(t, product2, top[1].Product.ID)
+
+	top, err = service.TopLiked(ctx, Page{Number: 2, Size: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int64{"Contoh Product 3": 2}, rankedProducts(top))
+
+	also, err := service.AlsoLiked(ctx, product1, Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(also))
+	assert.Equal(t, product2, also[0].Product.ID)
+	assert.Equal(t, map[string]int64{"Contoh Product 2": 2, "Contoh Product 3": 1}, rankedProducts(also))
+
+	also, err = service.AlsoLiked(ctx, 999, Page{})
+	assert.Nil(t, err)
+	assert.Empty(t, also)
+}
+
+func TestTrending(t *testing.T) {
+	db, seed := setupTestDB(t)
+	now := time.Now().Add(2 * time.Hour)
+	service := &PopularityService{db: db, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	//like dari fixture sudah lebih dari satu jam yang lalu
+	recent := now.Add(-30 * time.Minute)
+	likes := []UserLikeProduct{
+		{UserId: seed.Users["megumi"], ProductId: seed.Products["product_3"], LikedAt: &recent},
+		{UserId: seed.Users["suguru"], ProductId: seed.Products["product_3"], LikedAt: &recent},
+		{UserId: seed.Users["megumi"], ProductId: seed.Products["product_2"], LikedAt: &recent},
+	}
+	assert.Nil(t, db.Create(&likes).Error)
+
+	trending, err := service.Trending(ctx, time.Hour, Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(trending))
+	assert.Equal(t, seed.Products["product_3"], trending[0].Product.ID)
+	assert.Equal(t, map[string]int64{"Contoh Product 3": 2, "Contoh Product 2": 1}, rankedProducts(trending))
+
+	trending, err = service.Trending(ctx, 3*time.Hour, Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int64{"Contoh Product 1": 1, "Contoh Product 2": 1, "Contoh Product 3": 3}, rankedProducts(trending))
+}
+
+func TestLikeMetadataThroughAssociation(t *testing.T) {
+	db, seed := setupTestDB(t)
+	rating := 4
+	ctx := WithLikeMetadata(context.Background(), LikeMetadata{Rating: &rating, Source: "mobile"})
+
+	var product Product
+	assert.Nil(t, db.Take(&product, "id = ?", seed.Products["product_2"]).Error)
+	err := db.WithContext(ctx).Model(&User{ID: seed.Users["nanami"]}).Association("LikeProducts").Append(&product)
+	assert.Nil(t, err)
+
+	var like UserLikeProduct
+	assert.Nil(t, db.Take(&like, "user_id = ? AND product_id = ?", seed.Users["nanami"], product.ID).Error)
+	assert.Equal(t, 4, *like.Rating)
+	assert.Equal(t, "mobile", like.Source)
+
+	//tanpa metadata, source default dan rating kosong
+	likeProducts(t, db, seed.Users["toji"], product.ID)
+	like = UserLikeProduct{}
+	assert.Nil(t, db.Take(&like, "user_id = ? AND product_id = ?", seed.Users["toji"], product.ID).Error)
+	assert.Nil(t, like.Rating)
+	assert.Equal(t, LikeSourceDirect, like.Source)
+
+	rating = 6
+	err = db.WithContext(ctx).Model(&User{ID: seed.Users["laksa"]}).Association("LikeProducts").Append(&product)
+	assert.True(t, errors.Is(err, ErrInvalidRating))
+}
+
+func TestLikeServiceMetadata(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewLikeService(db)
+	ctx := context.Background()
+	product := seed.Products["product_1"]
+	three, five := 3, 5
+
+	first, err := service.Like(ctx, seed.Users["nanami"], product, LikeMetadata{Rating: &three, Source: "web"})
+	assert.Nil(t, err)
+	assert.NotNil(t, first.LikedAt)
+
+	//like ulang memperbarui rating dan source, liked_at tetap
+	again, err := service.Like(ctx, seed.Users["nanami"], product, LikeMetadata{Rating: &five, Source: "mobile"})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, *again.Rating)
+	assert.Equal(t, "mobile", again.Source)
+	assert.True(t, first.LikedAt.Equal(*again.LikedAt))
+
+	//like ulang tanpa rating, rating lama tetap
+	again, err = service.Like(ctx, seed.Users["nanami"], product, LikeMetadata{Source: "web"})
+	assert.Nil(t, err)
+	assert.Equal(t, &five, again.Rating)
+	assert.Equal(t, "web", again.Source)
+
+	//like ulang tanpa source, source lama tetap (bukan direct)
+	again, err = service.Like(ctx, seed.Users["nanami"], product, LikeMetadata{Rating: &three})
+	assert.Nil(t, err)
+	assert.Equal(t, &three, again.Rating)
+	assert.Equal(t, "web", again.Source)
+
+	//tanpa metadata sama sekali, like lama tidak berubah
+	again, err = service.Like(ctx, seed.Users["nanami"], product, LikeMetadata{})
+	assert.Nil(t, err)
+	assert.Equal(t, &three, again.Rating)
+	assert.Equal(t, "web", again.Source)
+	assert.True(t, first.LikedAt.Equal(*again.LikedAt))
+
+	_, err = service.Like(ctx, seed.Users["toji"], product, LikeMetadata{Rating: &three})
+	assert.Nil(t, err)
+	_, err = service.Like(ctx, seed.Users["toji"], 999, LikeMetadata{})
+	assert.True(t, errors.Is(err, ErrProductNotFound))
+
+	//fixture: user_c menyukai product_1 tanpa rating
+	likes, err := service.ListByProduct(ctx, product, Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(likes))
+	for _, like := range likes {
+		assert.NotNil(t, like.User)
+		assert.Equal(t, like.UserId, like.User.ID)
+	}
+
+	average, count, err := service.AverageRating(ctx, product)
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, average)
+	assert.Equal(t, int64(2), count)
+
+	byUser, err := service.ListByUser(ctx, seed.Users["nanami"], Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(byUser))
+	assert.Equal(t, "Contoh Product 1", byUser[0].Product.Name)
+	assert.Equal(t, "web", byUser[0].Source)
+
+	assert.Nil(t, service.Unlike(ctx, seed.Users["nanami"], product))
+	byUser, err = service.ListByUser(ctx, seed.Users["nanami"], Page{})
+	assert.Nil(t, err)
+	assert.Empty(t, byUser)
+}
@@ -0,0 +1,423 @@
+// Package migrate menjalankan migrasi schema yang berversi (up/down) dan mencatatnya di tabel schema_migrations.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTable -> nama tabel untuk mencatat migrasi yang sudah dijalankan
+const DefaultTable = "schema_migrations"
+
+var (
+	ErrChecksumMismatch = errors.New("migrate: applied migration was edited")
+	ErrIrreversible     = errors.New("migrate: migration has no down step")
+	ErrInvalidMigration = errors.New("migrate: invalid migration")
+)
+
+// Migration -> satu langkah perubahan schema.
+// Isi Up/Down (fungsi go) atau UpSQL/DownSQL (raw sql, boleh beberapa statement dipisah ";").
+type Migration struct {
+	Version string // harus unik dan bisa diurutkan, contoh: 20231101000001
+	Name    string
+
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+
+	UpSQL   string
+	DownSQL string
+
+	// DisableTransaction -> jalankan tanpa transaksi (misal CREATE INDEX CONCURRENTLY di postgres)
+	DisableTransaction bool
+}
+
+// Checksum -> sha256 dari versi, nama dan sql migrasi.
+// Isi fungsi go tidak ikut dihitung, jadi ubah Name jika migrasi go diubah.
+func (m Migration) Checksum() string {
+	hash := sha256.New()
+	for _, part := range []string{m.Version, m.Name, m.UpSQL, m.DownSQL} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (m Migration) hasUp() bool {
+	return m.Up != nil || strings.TrimSpace(m.UpSQL) != ""
+}
+
+func (m Migration) hasDown() bool {
+	return m.Down != nil || strings.TrimSpace(m.DownSQL) != ""
+}
+
+// ChecksumError -> migrasi yang sudah dijalankan ternyata isinya diubah
+type ChecksumError struct {
+	Version  string
+	Expected string // checksum yang tercatat di database
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: version %s recorded checksum %s, got %s", ErrChecksumMismatch, e.Version, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// Status -> keadaan satu migrasi
+type Status struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified"` // checksum berbeda dengan yang tercatat
+	Unknown   bool       `json:"unknown"`  // tercatat di database tapi tidak ada di daftar migrasi
+}
+
+type record struct {
+	Version   string    `gorm:"primaryKey;column:version;size:64"`
+	Name      string    `gorm:"column:name;size:255"`
+	Checksum  string    `gorm:"column:checksum;size:64"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+// Migrator -> menjalankan daftar migrasi terhadap satu database
+type Migrator struct {
+	db         *gorm.DB
+	table      string
+	migrations []Migration
+}
+
+// New -> membuat Migrator, migrasi akan diurutkan berdasarkan Version
+func New(db *gorm.DB, migrations ...Migration) (*Migrator, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	seen := map[string]bool{}
+	for _, migration := range sorted {
+		if migration.Version == "" {
+			return nil, fmt.Errorf("%w: empty version (%s)", ErrInvalidMigration, migration.Name)
+		}
+		if seen[migration.Version] {
+			return nil, fmt.Errorf("%w: duplicate version %s", ErrInvalidMigration, migration.Version)
+		}
+		if !migration.hasUp() {
+			return nil, fmt.Errorf("%w: version %s has no up step", ErrInvalidMigration, migration.Version)
+		}
+		if migration.Up != nil && migration.UpSQL != "" || migration.Down != nil && migration.DownSQL != "" {
+			return nil, fmt.Errorf("%w: version %s mixes go and sql steps", ErrInvalidMigration, migration.Version)
+		}
+		seen[migration.Version] = true
+	}
+
+	return &Migrator{db: db, table: DefaultTable, migrations: sorted}, nil
+}
+
+// WithTable -> memakai nama tabel pencatat selain schema_migrations
+func (m *Migrator) WithTable(table string) *Migrator {
+	clone := *m
+	clone.table = table
+	return &clone
+}
+
+// Up -> menjalankan semua migrasi yang belum dijalankan
+func (m *Migrator) Up(ctx context.Context) error {
+	applied, err := m.load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, migration, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down -> membatalkan n migrasi terakhir yang sudah dijalankan
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	applied, err := m.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := m.run(ctx, migration, false); err != nil {
+			return err
+		}
+		n--
+	}
+	return nil
+}
+
+// Redo -> membatalkan lalu menjalankan ulang migrasi terakhir
+func (m *Migrator) Redo(ctx context.Context) error {
+	applied, err := m.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := m.run(ctx, migration, false); err != nil {
+			return err
+		}
+		return m.run(ctx, migration, true)
+	}
+	return nil
+}
+
+// Status -> daftar semua migrasi beserta keadaannya, urut berdasarkan versi
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if rec, ok := applied[migration.Version]; ok {
+			appliedAt := rec.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = rec.Checksum != migration.Checksum()
+		}
+		result = append(result, status)
+	}
+	for version, rec := range applied {
+		if m.find(version) == nil {
+			appliedAt := rec.AppliedAt
+			result = append(result, Status{Version: version, Name: rec.Name, Applied: true, AppliedAt: &appliedAt, Unknown: true})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+func (m *Migrator) find(version string) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) verify(applied map[string]record) error {
+	for _, migration := range m.migrations {
+		rec, ok := applied[migration.Version]
+		if ok && rec.Checksum != migration.Checksum() {
+			return &ChecksumError{Version: migration.Version, Expected: rec.Checksum, Actual: migration.Checksum()}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) load(ctx context.Context) (map[string]record, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(m.table) {
+		if err := db.Table(m.table).Migrator().CreateTable(&record{}); err != nil {
+			return nil, fmt.Errorf("migrate: create %s: %w", m.table, err)
+		}
+	}
+
+	var records []record
+	if err := db.Table(m.table).Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("migrate: read %s: %w", m.table, err)
+	}
+
+	applied := make(map[string]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// transactionalDDL -> mysql melakukan implicit commit untuk DDL, jadi transaksi tidak ada gunanya
+func transactionalDDL(db *gorm.DB) bool {
+	return db.Dialector.Name() != "mysql"
+}
+
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) error {
+	direction := "up"
+	if !up {
+		direction = "down"
+		if !migration.hasDown() {
+			return fmt.Errorf("%w: %s %s", ErrIrreversible, migration.Version, migration.Name)
+		}
+	}
+
+	step := func(tx *gorm.DB) error {
+		var err error
+		switch {
+		case up && migration.Up != nil:
+			err = migration.Up(tx)
+		case up:
+			err = execSQL(tx, migration.UpSQL)
+		case migration.Down != nil:
+			err = migration.Down(tx)
+		default:
+			err = execSQL(tx, migration.DownSQL)
+		}
+		if err != nil {
+			return err
+		}
+
+		if up {
+			return tx.Table(m.table).Create(&record{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum(),
+				AppliedAt: time.Now(),
+			}).Error
+		}
+		return tx.Table(m.table).Where("version = ?", migration.Version).Delete(&record{}).Error
+	}
+
+	db := m.db.WithContext(ctx)
+	transaction := !migration.DisableTransaction && transactionalDDL(db)
+	var err error
+	switch {
+	case db.Dialector.Name() == "sqlite":
+		err = db.Connection(func(conn *gorm.DB) error {
+			return sqliteWithoutForeignKeys(conn, step, transaction)
+		})
+	case transaction:
+		err = db.Transaction(step)
+	default:
+		err = step(db)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: %s %s %s: %w", direction, migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// sqliteWithoutForeignKeys -> sqlite mengubah tabel dengan membuat ulang tabel (DROP TABLE yang lama), jika foreign key
+// aktif DROP TABLE ikut menghapus baris anak (ON DELETE CASCADE). Mengikuti prosedur di dokumentasi sqlite:
+// foreign key dimatikan sebelum transaksi (PRAGMA di dalam transaksi tidak berpengaruh), foreign_key_check
+// sebelum commit, lalu setting semula dikembalikan. conn harus satu koneksi karena PRAGMA berlaku per koneksi
+func sqliteWithoutForeignKeys(conn *gorm.DB, step func(tx *gorm.DB) error, transaction bool) (err error) {
+	conn = conn.Session(&gorm.Session{NewDB: true}) //setiap query mulai dari statement baru, tetap di koneksi yang sama
+	var enabled bool
+	if err := conn.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+		return err
+	}
+	if enabled {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer func() {
+			if restoreErr := conn.Exec("PRAGMA foreign_keys = ON").Error; err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+
+	checked := func(tx *gorm.DB) error {
+		if err := step(tx); err != nil {
+			return err
+		}
+		return foreignKeyCheck(tx)
+	}
+	if !transaction {
+		return checked(conn)
+	}
+	return conn.Transaction(checked)
+}
+
+// ForeignKeyError -> migrasi sqlite meninggalkan baris yang melanggar foreign key
+type ForeignKeyError struct {
+	Table  string
+	RowID  int64
+	Parent string
+}
+
+func (e *ForeignKeyError) Error() string {
+	return fmt.Sprintf("migrate: %s row %d violates foreign key to %s", e.Table, e.RowID, e.Parent)
+}
+
+func foreignKeyCheck(tx *gorm.DB) error {
+	var violations []struct {
+		Table  string
+		Rowid  *int64
+		Parent string
+	}
+	if err := tx.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	violation := &ForeignKeyError{Table: violations[0].Table, Parent: violations[0].Parent}
+	if violations[0].Rowid != nil {
+		violation.RowID = *violations[0].Rowid
+	}
+	return violation
+}
+
+func execSQL(tx *gorm.DB, sql string) error {
+	for _, statement := range splitStatements(sql) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements -> memecah sql berdasarkan ";" yang tidak berada di dalam tanda kutip
+func splitStatements(sql string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for _, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ';':
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return statements
+}
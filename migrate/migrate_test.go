@@ -0,0 +1,236 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.Nil(t, err)
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func sampleMigrations() []Migration {
+	return []Migration{
+		{
+			Version: "002",
+			Name:    "seed_sample",
+			Up: func(tx *gorm.DB) error {
+				return tx.Exec("insert into sample(name) values (?), (?)", "Eko", "Budi").Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Exec("delete from sample").Error
+			},
+		},
+		{
+			Version: "001",
+			Name:    "create_sample",
+			UpSQL:   "create table sample (id integer primary key, name varchar(100));\ncreate index idx_sample_name on sample (name);",
+			DownSQL: "drop table sample",
+		},
+	}
+}
+
+func TestUpStatusDown(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	migrator, err := New(db, sampleMigrations()...)
+	assert.Nil(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(statuses))
+	assert.Equal(t, "001", statuses[0].Version)
+	assert.False(t, statuses[0].Applied)
+
+	assert.Nil(t, migrator.Up(ctx))
+	assert.Nil(t, migrator.Up(ctx)) //kedua kali tidak ada yang dijalankan
+
+	var count int64
+	assert.Nil(t, db.Table("sample").Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	statuses, err = migrator.Status(ctx)
+	assert.Nil(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.NotNil(t, status.AppliedAt)
+		assert.False(t, status.Modified)
+	}
+
+	assert.Nil(t, migrator.Down(ctx, 1))
+	assert.Nil(t, db.Table("sample").Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	assert.Nil(t, migrator.Down(ctx, 5))
+	assert.False(t, db.Migrator().HasTable("sample"))
+}
+
+func TestRedo(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	migrator, err := New(db, sampleMigrations()...)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.Up(ctx))
+	assert.Nil(t, db.Exec("insert into sample(name) values (?)", "Joko").Error)
+
+	assert.Nil(t, migrator.Redo(ctx))
+
+	var count int64
+	assert.Nil(t, db.Table("sample").Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	migrator, err := New(db, sampleMigrations()...)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.Up(ctx))
+
+	edited := sampleMigrations()
+	edited[1].UpSQL = "create table sample (id integer primary key, name text)"
+	migrator, err = New(db, edited...)
+	assert.Nil(t, err)
+
+	err = migrator.Up(ctx)
+	var checksumErr *ChecksumError
+	assert.True(t, errors.As(err, &checksumErr))
+	assert.Equal(t, "001", checksumErr.Version)
+
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.True(t, statuses[0].Modified)
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	migrations := append(sampleMigrations(), Migration{
+		Version: "003",
+		Name:    "broken",
+		UpSQL:   "insert into sample(name) values ('Joko'); insert into missing_table values (1)",
+	})
+	migrator, err := New(db, migrations...)
+	assert.Nil(t, err)
+	assert.NotNil(t, migrator.Up(ctx))
+
+	var count int64
+	assert.Nil(t, db.Table("sample").Count(&count).Error)
+	assert.Equal(t, int64(2), count) //insert Joko ikut di rollback
+
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.False(t, statuses[2].Applied)
+}
+
+func TestIrreversibleAndUnknown(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	migrator, err := New(db, Migration{Version: "001", Name: "create", UpSQL: "create table sample (id integer)"})
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.Up(ctx))
+	assert.True(t, errors.Is(migrator.Down(ctx, 1), ErrIrreversible))
+
+	migrator, err = New(db, Migration{Version: "000", Name: "noop", UpSQL: "select 1"})
+	assert.Nil(t, err)
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(statuses))
+	assert.True(t, statuses[1].Unknown)
+}
+
+func TestNewInvalidMigrations(t *testing.T) {
+	_, err := New(nil, Migration{Version: "001", UpSQL: "select 1"}, Migration{Version: "001", UpSQL: "select 2"})
+	assert.True(t, errors.Is(err, ErrInvalidMigration))
+
+	_, err = New(nil, Migration{Version: "001"})
+	assert.True(t, errors.Is(err, ErrInvalidMigration))
+}
+
+// parentChildMigrations -> tabel anak dengan foreign key ON DELETE CASCADE, lalu tabel parent dibuat ulang seperti AlterColumn sqlite
+func parentChildMigrations() []Migration {
+	return []Migration{
+		{
+			Version: "001",
+			Name:    "create_parent_child",
+			UpSQL: `create table parent (id integer primary key, name varchar(10));
+				create table child (id integer primary key, parent_id integer, constraint fk_child_parent foreign key (parent_id) references parent (id) on delete cascade);
+				insert into parent (id, name) values (1, 'a');
+				insert into child (id, parent_id) values (1, 1);`,
+			DownSQL: "drop table child; drop table parent",
+		},
+		{
+			Version: "002",
+			Name:    "rebuild_parent",
+			UpSQL: `create table parent__temp (id integer primary key, name varchar(100));
+				insert into parent__temp select * from parent;
+				drop table parent;
+				alter table parent__temp rename to parent;`,
+		},
+	}
+}
+
+func TestSQLiteRebuildKeepsChildRows(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	assert.Nil(t, db.Exec("PRAGMA foreign_keys = ON").Error)
+
+	migrator, err := New(db, parentChildMigrations()...)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.Up(ctx))
+
+	var children int64
+	assert.Nil(t, db.Table("child").Count(&children).Error)
+	assert.Equal(t, int64(1), children) //tanpa mematikan foreign key, drop table parent ikut menghapus child
+
+	var enabled bool
+	assert.Nil(t, db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error)
+	assert.True(t, enabled)
+}
+
+func TestSQLiteForeignKeyCheck(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	assert.Nil(t, db.Exec("PRAGMA foreign_keys = ON").Error)
+
+	migrations := parentChildMigrations()
+	migrations = append(migrations, Migration{Version: "003", Name: "orphan_child", UpSQL: "delete from parent"})
+	migrations[1].UpSQL = "insert into child (id, parent_id) values (2, 1)"
+	migrator, err := New(db, migrations...)
+	assert.Nil(t, err)
+
+	var violation *ForeignKeyError
+	assert.True(t, errors.As(migrator.Up(ctx), &violation))
+	assert.Equal(t, "child", violation.Table)
+	assert.Equal(t, "parent", violation.Parent)
+
+	//migrasi yang gagal di rollback, foreign key tetap aktif
+	var parents int64
+	assert.Nil(t, db.Table("parent").Count(&parents).Error)
+	assert.Equal(t, int64(1), parents)
+	var enabled bool
+	assert.Nil(t, db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error)
+	assert.True(t, enabled)
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements("insert into sample(name) values ('a;b');\n\n  update sample set name = \"x;\" ;")
+	assert.Equal(t, []string{"insert into sample(name) values ('a;b')", "update sample set name = \"x;\""}, statements)
+}
@@ -0,0 +1,858 @@
+package golanggorm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-gorm/migrate"
+	"gorm.io/gorm"
+)
+
+// Migrations -> daftar migrasi schema, urut berdasarkan versi.
+// Struct di dalam migrasi adalah snapshot schema pada versi tersebut, jangan diganti dengan model yang sekarang.
+func Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version: "20231101000001",
+			Name:    "create_initial_schema",
+			Up: func(tx *gorm.DB) error {
+				tables := []struct {
+					name  string
+					model interface{}
+				}{
+					{"users", &struct {
+						ID         int       `gorm:"primaryKey;column:id;autoIncrement"`
+						Password   string    `gorm:"column:password;size:255"`
+						FirstName  string    `gorm:"column:first_name;size:100"`
+						MiddleName string    `gorm:"column:middle_name;size:100"`
+						LastName   string    `gorm:"column:last_name;size:100"`
+						CreatedAt  time.Time `gorm:"column:created_at"`
+						UpdatedAt  time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"wallets", &struct {
+						ID        int       `gorm:"primaryKey;column:id;autoIncrement"`
+						UserId    int       `gorm:"column:user_id;uniqueIndex:idx_wallets_user_id"` //User.Wallet has one
+						Balance   int64     `gorm:"column:balance"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+						UpdatedAt time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"addresses", &struct {
+						ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`
+						UserId    string    `gorm:"column:user_id;size:100"`
+						Address   string    `gorm:"column:address;size:255"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+						UpdatedAt time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"products", &struct {
+						ID        int       `gorm:"primaryKey;column:id;autoIncrement"`
+						Name      string    `gorm:"column:name;size:100"`
+						Price     int64     `gorm:"column:price"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+						UpdatedAt time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"user_like_product", &struct {
+						UserId    int `gorm:"primaryKey;column:user_id;autoIncrement:false"`
+						ProductId int `gorm:"primaryKey;column:product_id;autoIncrement:false"`
+					}{}},
+					{"todos", &struct {
+						ID          uint           `gorm:"primaryKey;column:id;autoIncrement"`
+						CreatedAt   time.Time      `gorm:"column:created_at"`
+						UpdatedAt   time.Time      `gorm:"column:updated_at"`
+						DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index"`
+						UserId      string         `gorm:"column:user_id;size:100"`
+						Title       string         `gorm:"column:title;size:255"`
+						Description string         `gorm:"column:description"`
+					}{}},
+					{"user_logs", &struct {
+						ID        int    `gorm:"primaryKey;column:id;autoIncrement"`
+						UserId    string `gorm:"column:user_id;size:100"`
+						Action    string `gorm:"column:action;size:255"`
+						CreatedAt int64  `gorm:"column:created_at"`
+						UpdatedAt int64  `gorm:"column:updated_at"`
+					}{}},
+				}
+				for _, table := range tables {
+					if err := tx.Table(table.name).Migrator().CreateTable(table.model); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("user_logs", "todos", "user_like_product", "products", "addresses", "wallets", "users")
+			},
+		},
+		{
+			Version: "20231101000002",
+			Name:    "create_guest_books",
+			Up: func(tx *gorm.DB) error {
+				return tx.Table("guest_books").Migrator().CreateTable(&struct {
+					ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`
+					Name      string    `gorm:"column:name;size:100"`
+					Email     string    `gorm:"column:email;size:100"`
+					Message   string    `gorm:"column:message"`
+					CreatedAt time.Time `gorm:"column:created_at"`
+					UpdatedAt time.Time `gorm:"column:updated_at"`
+				}{})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("guest_books")
+			},
+		},
+		{
+			Version: "20231101000003",
+			Name:    "create_ledger_entries",
+			Up: func(tx *gorm.DB) error {
+				err := tx.Table("ledger_entries").Migrator().CreateTable(&struct {
+					ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`
+					WalletId  int       `gorm:"column:wallet_id;index:idx_ledger_entries_wallet_id"`
+					Amount    int64     `gorm:"column:amount"`
+					Direction string    `gorm:"column:direction;size:10"`
+					Reference string    `gorm:"column:reference;size:100;index:idx_ledger_entries_reference"`
+					CreatedAt time.Time `gorm:"column:created_at"`
+				}{})
+				if err != nil {
+					return err
+				}
+
+				//saldo yang sudah ada dijadikan saldo awal di ledger
+				var wallets []struct {
+					ID      int
+					Balance int64
+				}
+				if err := tx.Table("wallets").Select("id", "balance").Where("balance <> 0").Find(&wallets).Error; err != nil {
+					return err
+				}
+				now := time.Now()
+				for _, wallet := range wallets {
+					direction, counter, amount := "credit", "debit", wallet.Balance
+					if amount < 0 {
+						direction, counter, amount = "debit", "credit", -amount
+					}
+					reference := "opening-" + strconv.Itoa(wallet.ID)
+					rows := []map[string]interface{}{
+						{"wallet_id": wallet.ID, "amount": amount, "direction": direction, "reference": reference, "created_at": now},
+						{"wallet_id": 0, "amount": amount, "direction": counter, "reference": reference, "created_at": now},
+					}
+					if err := tx.Table("ledger_entries").Create(rows).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("ledger_entries")
+			},
+		},
+		{
+			Version: "20231101000004",
+			Name:    "create_idempotency_keys",
+			Up: func(tx *gorm.DB) error {
+				return tx.Table("idempotency_keys").Migrator().CreateTable(&struct {
+					Key         string    `gorm:"primaryKey;column:idempotency_key;size:100"`
+					RequestHash string    `gorm:"column:request_hash;size:64"`
+					Response    string    `gorm:"column:response"`
+					CreatedAt   time.Time `gorm:"column:created_at"`
+				}{})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("idempotency_keys")
+			},
+		},
+		{
+			Version: "20231101000005",
+			Name:    "add_version_columns",
+			Up: func(tx *gorm.DB) error {
+				column := &struct {
+					Version int64 `gorm:"column:version;not null;default:1"`
+				}{}
+				for _, table := range []string{"users", "wallets", "products"} {
+					if err := tx.Table(table).Migrator().AddColumn(column, "Version"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				column := &struct {
+					Version int64 `gorm:"column:version;not null;default:1"`
+				}{}
+				for _, table := range []string{"users", "wallets", "products"} {
+					if err := tx.Table(table).Migrator().DropColumn(column, "Version"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: "20231101000006",
+			Name:    "add_user_email",
+			Up: func(tx *gorm.DB) error {
+				//index dibuat terpisah, sqlite tidak bisa ADD COLUMN yang UNIQUE
+				users := &struct {
+					Email           *string    `gorm:"column:email;size:255"`
+					EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"`
+				}{}
+				migrator := tx.Table("users").Migrator()
+				for _, field := range []string{"Email", "EmailVerifiedAt"} {
+					if err := migrator.AddColumn(users, field); err != nil {
+						return err
+					}
+				}
+				if err := tx.Exec("CREATE UNIQUE INDEX idx_users_email ON users (email)").Error; err != nil {
+					return err
+				}
+				return tx.Table("user_email_verifications").Migrator().CreateTable(&struct {
+					ID        int64      `gorm:"primaryKey;column:id;autoIncrement"`
+					UserId    int        `gorm:"column:user_id;index:idx_user_email_verifications_user_id"`
+					Email     string     `gorm:"column:email;size:255"`
+					TokenHash string     `gorm:"column:token_hash;size:64;uniqueIndex:idx_user_email_verifications_token_hash"`
+					ExpiresAt time.Time  `gorm:"column:expires_at"`
+					UsedAt    *time.Time `gorm:"column:used_at"`
+					CreatedAt time.Time  `gorm:"column:created_at"`
+				}{})
+			},
+			Down: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("user_email_verifications"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropIndex("users", "idx_users_email"); err != nil {
+					return err
+				}
+				users := &struct {
+					Email           *string    `gorm:"column:email;size:255"`
+					EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"`
+				}{}
+				migrator := tx.Table("users").Migrator()
+				for _, field := range []string{"EmailVerifiedAt", "Email"} {
+					if err := migrator.DropColumn(users, field); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: "20231101000007",
+			Name:    "create_api_tokens",
+			Up: func(tx *gorm.DB) error {
+				err := tx.Table("api_tokens").Migrator().CreateTable(&struct {
+					ID         int64      `gorm:"primaryKey;column:id;autoIncrement"`
+					UserId     int        `gorm:"column:user_id;index:idx_api_tokens_user_id"`
+					Name       string     `gorm:"column:name;size:100"`
+					TokenHash  string     `gorm:"column:token_hash;size:64;uniqueIndex:idx_api_tokens_token_hash"`
+					Scopes     string     `gorm:"column:scopes;size:255"`
+					ExpiresAt  time.Time  `gorm:"column:expires_at;index:idx_api_tokens_expires_at"`
+					LastUsedAt *time.Time `gorm:"column:last_used_at"`
+					RevokedAt  *time.Time `gorm:"column:revoked_at"`
+					CreatedAt  time.Time  `gorm:"column:created_at"`
+				}{})
+				if err != nil {
+					return err
+				}
+				return addForeignKeys(tx, "api_tokens",
+					foreignKey{name: "fk_api_tokens_user", column: "user_id", references: "users"})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("api_tokens")
+			},
+		},
+		{
+			Version: "20231101000008",
+			Name:    "create_roles_and_permissions",
+			Up: func(tx *gorm.DB) error {
+				tables := []struct {
+					name  string
+					model interface{}
+				}{
+					{"roles", &struct {
+						ID        int       `gorm:"primaryKey;column:id;autoIncrement"`
+						Name      string    `gorm:"column:name;size:100;uniqueIndex:idx_roles_name"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+						UpdatedAt time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"permissions", &struct {
+						ID        int       `gorm:"primaryKey;column:id;autoIncrement"`
+						Name      string    `gorm:"column:name;size:100;uniqueIndex:idx_permissions_name_scope"`
+						Scope     string    `gorm:"column:scope;size:10;uniqueIndex:idx_permissions_name_scope"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+						UpdatedAt time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"user_roles", &struct {
+						UserId int `gorm:"primaryKey;column:user_id;autoIncrement:false"`
+						RoleId int `gorm:"primaryKey;column:role_id;autoIncrement:false"`
+					}{}},
+					{"role_permissions", &struct {
+						RoleId       int `gorm:"primaryKey;column:role_id;autoIncrement:false"`
+						PermissionId int `gorm:"primaryKey;column:permission_id;autoIncrement:false"`
+					}{}},
+				}
+				for _, table := range tables {
+					if err := tx.Table(table.name).Migrator().CreateTable(table.model); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("role_permissions", "user_roles", "permissions", "roles")
+			},
+		},
+		{
+			Version: "20231101000009",
+			Name:    "add_user_logs_audit_columns",
+			Up: func(tx *gorm.DB) error {
+				logs := &struct {
+					Entity   string `gorm:"column:entity;size:100;index:idx_user_logs_entity"`
+					RecordId string `gorm:"column:record_id;size:100;index:idx_user_logs_entity"`
+					Changes  string `gorm:"column:changes"`
+				}{}
+				migrator := tx.Table("user_logs").Migrator()
+				for _, field := range []string{"Entity", "RecordId", "Changes"} {
+					if err := migrator.AddColumn(logs, field); err != nil {
+						return err
+					}
+				}
+				return migrator.CreateIndex(logs, "idx_user_logs_entity")
+			},
+			Down: func(tx *gorm.DB) error {
+				logs := &struct {
+					Entity   string `gorm:"column:entity;size:100;index:idx_user_logs_entity"`
+					RecordId string `gorm:"column:record_id;size:100;index:idx_user_logs_entity"`
+					Changes  string `gorm:"column:changes"`
+				}{}
+				migrator := tx.Table("user_logs").Migrator()
+				if err := migrator.DropIndex(logs, "idx_user_logs_entity"); err != nil {
+					return err
+				}
+				for _, field := range []string{"Changes", "RecordId", "Entity"} {
+					if err := migrator.DropColumn(logs, field); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: "20231101000010",
+			Name:    "add_todo_parent_id",
+			Up: func(tx *gorm.DB) error {
+				todo := &struct {
+					ParentID *uint `gorm:"column:parent_id;index:idx_todos_parent_id"`
+				}{}
+				migrator := tx.Table("todos").Migrator()
+				if err := migrator.AddColumn(todo, "ParentID"); err != nil {
+					return err
+				}
+				if err := migrator.CreateIndex(todo, "idx_todos_parent_id"); err != nil {
+					return err
+				}
+				return addForeignKeys(tx, "todos",
+					foreignKey{name: "fk_todos_subtasks", column: "parent_id", references: "todos"})
+			},
+			Down: func(tx *gorm.DB) error {
+				todo := &struct {
+					ParentID *uint `gorm:"column:parent_id;index:idx_todos_parent_id"`
+				}{}
+				//constraint dihapus lebih dulu, kolom yang masih dipakai foreign key tidak bisa di drop
+				err := keepIndexes(tx, "todos", func() error {
+					return tx.Migrator().DropConstraint("todos", "fk_todos_subtasks")
+				})
+				if err != nil {
+					return err
+				}
+				migrator := tx.Table("todos").Migrator()
+				if err := migrator.DropIndex(todo, "idx_todos_parent_id"); err != nil {
+					return err
+				}
+				return migrator.DropColumn(todo, "ParentID")
+			},
+		},
+		{
+			Version: "20231101000011",
+			Name:    "add_todo_tracking_and_tags",
+			Up: func(tx *gorm.DB) error {
+				todo := &struct {
+					Status      string     `gorm:"column:status;size:20;not null;default:open;index:idx_todos_status"`
+					Priority    int        `gorm:"column:priority;not null;default:2"`
+					DueAt       *time.Time `gorm:"column:due_at;index:idx_todos_due_at"`
+					CompletedAt *time.Time `gorm:"column:completed_at"`
+				}{}
+				migrator := tx.Table("todos").Migrator()
+				for _, field := range []string{"Status", "Priority", "DueAt", "CompletedAt"} {
+					if err := migrator.AddColumn(todo, field); err != nil {
+						return err
+					}
+				}
+				for _, index := range []string{"idx_todos_status", "idx_todos_due_at"} {
+					if err := migrator.CreateIndex(todo, index); err != nil {
+						return err
+					}
+				}
+
+				tables := []struct {
+					name  string
+					model interface{}
+				}{
+					{"tags", &struct {
+						ID        uint      `gorm:"primaryKey;column:id;autoIncrement"`
+						Name      string    `gorm:"column:name;size:50;uniqueIndex:idx_tags_name"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+					}{}},
+					{"todo_tags", &struct {
+						TodoId uint `gorm:"primaryKey;column:todo_id;autoIncrement:false"`
+						TagId  uint `gorm:"primaryKey;column:tag_id;autoIncrement:false"`
+					}{}},
+				}
+				for _, table := range tables {
+					if err := tx.Table(table.name).Migrator().CreateTable(table.model); err != nil {
+						return err
+					}
+				}
+				//tag ikut terhapus bersama todo atau tag-nya
+				return addForeignKeys(tx, "todo_tags",
+					foreignKey{name: "fk_todo_tags_todo", column: "todo_id", references: "todos"},
+					foreignKey{name: "fk_todo_tags_tag", column: "tag_id", references: "tags"})
+			},
+			Down: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("todo_tags", "tags"); err != nil {
+					return err
+				}
+				todo := &struct {
+					Status      string     `gorm:"column:status;size:20;not null;default:open;index:idx_todos_status"`
+					Priority    int        `gorm:"column:priority;not null;default:2"`
+					DueAt       *time.Time `gorm:"column:due_at;index:idx_todos_due_at"`
+					CompletedAt *time.Time `gorm:"column:completed_at"`
+				}{}
+				migrator := tx.Table("todos").Migrator()
+				for _, index := range []string{"idx_todos_due_at", "idx_todos_status"} {
+					if err := migrator.DropIndex(todo, index); err != nil {
+						return err
+					}
+				}
+				//sqlite membuat ulang tabel saat drop column, idx_todos_parent_id harus dibuat lagi
+				for _, field := range []string{"CompletedAt", "DueAt", "Priority", "Status"} {
+					err := keepIndexes(tx, "todos", func() error {
+						return migrator.DropColumn(todo, field)
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: "20231101000012",
+			Name:    "convert_user_id_to_integer",
+			Up: func(tx *gorm.DB) error {
+				//dibatalkan jika ada user_id yang tidak bisa menjadi foreign key, lihat FindOrphans
+				orphans, err := findOrphans(tx)
+				if err != nil {
+					return err
+				}
+				if len(orphans) > 0 {
+					return &OrphanError{Rows: orphans}
+				}
+				//log tanpa actor disimpan sebagai NULL, string kosong tidak bisa di cast ke integer
+				if err := tx.Table("user_logs").Where("user_id = ?", "").Update("user_id", nil).Error; err != nil {
+					return err
+				}
+
+				column := &struct {
+					UserId int `gorm:"column:user_id"`
+				}{}
+				for _, fk := range userForeignKeys {
+					if fk.integer {
+						continue
+					}
+					if err := alterColumnKeepIndexes(tx, fk.table, column, "UserId"); err != nil {
+						return err
+					}
+				}
+				todo := &struct {
+					UserId int `gorm:"column:user_id;index:idx_todos_user_id"`
+				}{}
+				if err := tx.Table("todos").Migrator().CreateIndex(todo, "idx_todos_user_id"); err != nil {
+					return err
+				}
+
+				//di sqlite tabel dibuat ulang dengan constraint, lihat addForeignKeys
+				for _, table := range userConstraints {
+					if err := addForeignKeys(tx, table.name, table.foreignKeys...); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				for _, table := range userConstraints {
+					for _, fk := range table.foreignKeys {
+						err := keepIndexes(tx, table.name, func() error {
+							return tx.Migrator().DropConstraint(table.name, fk.name)
+						})
+						if err != nil {
+							return err
+						}
+					}
+				}
+				if err := tx.Migrator().DropIndex("todos", "idx_todos_user_id"); err != nil {
+					return err
+				}
+				column := &struct {
+					UserId string `gorm:"column:user_id;size:100"`
+				}{}
+				for _, fk := range userForeignKeys {
+					if fk.integer {
+						continue
+					}
+					if err := alterColumnKeepIndexes(tx, fk.table, column, "UserId"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: "20231101000013",
+			Name:    "create_orders",
+			Up: func(tx *gorm.DB) error {
+				tables := []struct {
+					name  string
+					model interface{}
+				}{
+					{"orders", &struct {
+						ID              int       `gorm:"primaryKey;column:id;autoIncrement"`
+						UserId          int       `gorm:"column:user_id;index:idx_orders_user_id"`
+						Status          string    `gorm:"column:status;size:20"`
+						ItemCount       int       `gorm:"column:item_count"`
+						Total           int64     `gorm:"column:total"`
+						LedgerReference string    `gorm:"column:ledger_reference;size:100"`
+						CreatedAt       time.Time `gorm:"column:created_at"`
+						UpdatedAt       time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"order_details", &struct {
+						ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`
+						OrderId   int       `gorm:"column:order_id;index:idx_order_details_order_id"`
+						ProductId int       `gorm:"column:product_id"`
+						Quantity  int       `gorm:"column:quantity"`
+						UnitPrice int64     `gorm:"column:unit_price"`
+						Subtotal  int64     `gorm:"column:subtotal"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+					}{}},
+				}
+				for _, table := range tables {
+					if err := tx.Table(table.name).Migrator().CreateTable(table.model); err != nil {
+						return err
+					}
+				}
+				//order adalah riwayat transaksi, user dan product yang sudah dipesan tidak bisa dihapus
+				err := addForeignKeys(tx, "orders",
+					foreignKey{name: "fk_orders_user", column: "user_id", references: "users", onDelete: "RESTRICT"})
+				if err != nil {
+					return err
+				}
+				return addForeignKeys(tx, "order_details",
+					foreignKey{name: "fk_orders_details", column: "order_id", references: "orders"},
+					foreignKey{name: "fk_order_details_product", column: "product_id", references: "products", onDelete: "RESTRICT"})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("order_details", "orders")
+			},
+		},
+		{
+			Version: "20231101000014",
+			Name:    "add_product_stock_and_reservations",
+			Up: func(tx *gorm.DB) error {
+				product := &struct {
+					Stock int `gorm:"column:stock;not null;default:0"`
+				}{}
+				if err := tx.Table("products").Migrator().AddColumn(product, "Stock"); err != nil {
+					return err
+				}
+				//product lama sengaja stock 0 (tidak bisa dibeli) sampai stock sebenarnya diisi lewat Restock,
+				//tidak ada data stock yang bisa dijadikan backfill
+				err := tx.Table("stock_reservations").Migrator().CreateTable(&struct {
+					ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`
+					OrderId   int       `gorm:"column:order_id;index:idx_stock_reservations_order_id"`
+					ProductId int       `gorm:"column:product_id"`
+					Quantity  int       `gorm:"column:quantity"`
+					Status    string    `gorm:"column:status;size:20;index:idx_stock_reservations_status_expires_at"`
+					ExpiresAt time.Time `gorm:"column:expires_at;index:idx_stock_reservations_status_expires_at"`
+					CreatedAt time.Time `gorm:"column:created_at"`
+					UpdatedAt time.Time `gorm:"column:updated_at"`
+				}{})
+				if err != nil {
+					return err
+				}
+				//reservasi ikut hilang bersama order-nya, product dengan reservasi tidak bisa dihapus
+				return addForeignKeys(tx, "stock_reservations",
+					foreignKey{name: "fk_stock_reservations_order", column: "order_id", references: "orders"},
+					foreignKey{name: "fk_stock_reservations_product", column: "product_id", references: "products", onDelete: "RESTRICT"})
+			},
+			Down: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("stock_reservations"); err != nil {
+					return err
+				}
+				product := &struct {
+					Stock int `gorm:"column:stock;not null;default:0"`
+				}{}
+				return tx.Table("products").Migrator().DropColumn(product, "Stock")
+			},
+		},
+		{
+			Version: "20231101000015",
+			Name:    "create_categories",
+			Up: func(tx *gorm.DB) error {
+				tables := []struct {
+					name  string
+					model interface{}
+				}{
+					{"categories", &struct {
+						ID        int       `gorm:"primaryKey;column:id;autoIncrement"`
+						ParentId  *int      `gorm:"column:parent_id;index:idx_categories_parent_id"`
+						Name      string    `gorm:"column:name;size:100"`
+						CreatedAt time.Time `gorm:"column:created_at"`
+						UpdatedAt time.Time `gorm:"column:updated_at"`
+					}{}},
+					{"product_categories", &struct {
+						ProductId  int `gorm:"primaryKey;column:product_id;autoIncrement:false"`
+						CategoryId int `gorm:"primaryKey;column:category_id;autoIncrement:false;index:idx_product_categories_category_id"`
+					}{}},
+				}
+				for _, table := range tables {
+					if err := tx.Table(table.name).Migrator().CreateTable(table.model); err != nil {
+						return err
+					}
+				}
+				//anak kategori dipindah ke parent lewat CategoryService.Delete, jadi parent yang masih punya anak tidak bisa dihapus langsung
+				err := addForeignKeys(tx, "categories",
+					foreignKey{name: "fk_categories_children", column: "parent_id", references: "categories", onDelete: "RESTRICT"})
+				if err != nil {
+					return err
+				}
+				return addForeignKeys(tx, "product_categories",
+					foreignKey{name: "fk_product_categories_product", column: "product_id", references: "products"},
+					foreignKey{name: "fk_product_categories_category", column: "category_id", references: "categories"})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("product_categories", "categories")
+			},
+		},
+		{
+			Version: "20231101000016",
+			Name:    "add_user_like_product_created_at",
+			Up: func(tx *gorm.DB) error {
+				//nullable karena sqlite tidak bisa menambah kolom not null dengan default CURRENT_TIMESTAMP,
+				//like lama diisi waktu migrasi
+				like := &struct {
+					ProductId int        `gorm:"column:product_id;index:idx_user_like_product_product_id"`
+					CreatedAt *time.Time `gorm:"column:created_at;index:idx_user_like_product_created_at"`
+				}{}
+				migrator := tx.Table("user_like_product").Migrator()
+				if err := migrator.AddColumn(like, "CreatedAt"); err != nil {
+					return err
+				}
+				err := tx.Table("user_like_product").Where("created_at IS NULL").Update("created_at", time.Now()).Error
+				if err != nil {
+					return err
+				}
+				for _, index := range []string{"idx_user_like_product_product_id", "idx_user_like_product_created_at"} {
+					if err := migrator.CreateIndex(like, index); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				like := &struct {
+					ProductId int        `gorm:"column:product_id;index:idx_user_like_product_product_id"`
+					CreatedAt *time.Time `gorm:"column:created_at;index:idx_user_like_product_created_at"`
+				}{}
+				migrator := tx.Table("user_like_product").Migrator()
+				for _, index := range []string{"idx_user_like_product_created_at", "idx_user_like_product_product_id"} {
+					if err := migrator.DropIndex(like, index); err != nil {
+						return err
+					}
+				}
+				return migrator.DropColumn(like, "CreatedAt")
+			},
+		},
+		{
+			Version: "20231101000017",
+			Name:    "add_user_like_product_metadata",
+			Up: func(tx *gorm.DB) error {
+				like := &struct {
+					CreatedAt *time.Time `gorm:"column:created_at;index:idx_user_like_product_created_at"`
+					LikedAt   *time.Time `gorm:"column:liked_at;index:idx_user_like_product_liked_at"`
+					Rating    *int       `gorm:"column:rating"`
+					Source    string     `gorm:"column:source;size:50;not null;default:direct"`
+				}{}
+				migrator := tx.Table("user_like_product").Migrator()
+				if err := migrator.DropIndex(like, "idx_user_like_product_created_at"); err != nil {
+					return err
+				}
+				if err := migrator.RenameColumn(like, "created_at", "liked_at"); err != nil {
+					return err
+				}
+				for _, field := range []string{"Rating", "Source"} {
+					if err := migrator.AddColumn(like, field); err != nil {
+						return err
+					}
+				}
+				return migrator.CreateIndex(like, "idx_user_like_product_liked_at")
+			},
+			Down: func(tx *gorm.DB) error {
+				like := &struct {
+					CreatedAt *time.Time `gorm:"column:created_at;index:idx_user_like_product_created_at"`
+					LikedAt   *time.Time `gorm:"column:liked_at;index:idx_user_like_product_liked_at"`
+					Rating    *int       `gorm:"column:rating"`
+					Source    string     `gorm:"column:source;size:50;not null;default:direct"`
+				}{}
+				migrator := tx.Table("user_like_product").Migrator()
+				if err := migrator.DropIndex(like, "idx_user_like_product_liked_at"); err != nil {
+					return err
+				}
+				for _, field := range []string{"Source", "Rating"} {
+					err := keepIndexes(tx, "user_like_product", func() error {
+						return migrator.DropColumn(like, field)
+					})
+					if err != nil {
+						return err
+					}
+				}
+				if err := migrator.RenameColumn(like, "liked_at", "created_at"); err != nil {
+					return err
+				}
+				return migrator.CreateIndex(like, "idx_user_like_product_created_at")
+			},
+		},
+	}
+}
+
+// alterColumnKeepIndexes -> AlterColumn, di sqlite tabel dibuat ulang sehingga index lama harus dibuat lagi
+func alterColumnKeepIndexes(tx *gorm.DB, table string, model interface{}, field string) error {
+	return keepIndexes(tx, table, func() error {
+		return tx.Table(table).Migrator().AlterColumn(model, field)
+	})
+}
+
+// userConstraints -> foreign key yang ditambahkan migrasi convert_user_id_to_integer, per tabel
+var userConstraints = []struct {
+	name        string
+	foreignKeys []foreignKey
+}{
+	{"todos", []foreignKey{{name: "fk_users_todos", column: "user_id", references: "users"}}},
+	{"addresses", []foreignKey{{name: "fk_users_addresses", column: "user_id", references: "users"}}},
+	{"wallets", []foreignKey{{name: "fk_users_wallet", column: "user_id", references: "users"}}},
+	{"user_like_product", []foreignKey{
+		{name: "fk_user_like_product_user", column: "user_id", references: "users"},
+		{name: "fk_user_like_product_product", column: "product_id", references: "products"},
+	}},
+}
+
+// foreignKey -> foreign key yang ditambahkan migrasi, selalu menunjuk ke kolom id tabel references
+type foreignKey struct {
+	name       string
+	column     string
+	references string
+	onDelete   string //kosong berarti CASCADE
+}
+
+func (fk foreignKey) sql(quote func(interface{}) string) string {
+	onDelete := fk.onDelete
+	if onDelete == "" {
+		onDelete = "CASCADE"
+	}
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s) ON UPDATE CASCADE ON DELETE %s",
+		quote(fk.name), quote(fk.column), quote(fk.references), quote("id"), onDelete)
+}
+
+// addForeignKeys -> ALTER TABLE ADD CONSTRAINT, sqlite tidak mendukungnya sehingga tabel dibuat ulang
+// dengan constraint di DDL (index ikut dibuat lagi lewat keepIndexes). Dihapus lagi dengan Migrator().DropConstraint.
+// DROP TABLE aman karena migrate mematikan foreign key sqlite selama migrasi, lihat migrate.sqliteWithoutForeignKeys
+func addForeignKeys(tx *gorm.DB, table string, fks ...foreignKey) error {
+	quote := tx.Statement.Quote
+	if tx.Dialector.Name() != DialectSQLite {
+		for _, fk := range fks {
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD %s", quote(table), fk.sql(quote))).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return keepIndexes(tx, table, func() error {
+		var ddl string
+		err := tx.Raw("SELECT sql FROM sqlite_master WHERE type = ? AND name = ?", "table", table).Row().Scan(&ddl)
+		if err != nil {
+			return err
+		}
+		start, end := strings.Index(ddl, "("), strings.LastIndex(ddl, ")")
+		if start < 0 || end < start {
+			return fmt.Errorf("unexpected ddl for table %s: %s", table, ddl)
+		}
+		definitions := []string{ddl[start+1 : end]}
+		for _, fk := range fks {
+			definitions = append(definitions, fk.sql(quote))
+		}
+
+		temp := table + "__temp"
+		statements := []string{
+			fmt.Sprintf("CREATE TABLE %s (%s)", quote(temp), strings.Join(definitions, ",")),
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", quote(temp), quote(table)),
+			fmt.Sprintf("DROP TABLE %s", quote(table)),
+			fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quote(temp), quote(table)),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// keepIndexes -> menjalankan fn (AlterColumn, DropColumn), index sqlite yang hilang karena tabel dibuat ulang dibuat lagi
+func keepIndexes(tx *gorm.DB, table string, fn func() error) error {
+	var indexes []struct {
+		Name string
+		Sql  string
+	}
+	if tx.Dialector.Name() == DialectSQLite {
+		err := tx.Raw("SELECT name, sql FROM sqlite_master WHERE type = ? AND tbl_name = ? AND sql IS NOT NULL", "index", table).Scan(&indexes).Error
+		if err != nil {
+			return err
+		}
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if tx.Migrator().HasIndex(table, index.Name) {
+			continue
+		}
+		if err := tx.Exec(index.Sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewMigrator -> migrate.Migrator yang berisi semua Migrations()
+func NewMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	return migrate.New(db, Migrations()...)
+}
+
+// Migrate -> menjalankan semua migrasi yang belum dijalankan
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(ctx)
+}
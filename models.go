@@ -0,0 +1,26 @@
+package golanggorm
+
+// Models -> semua model di package ini, urut dari tabel yang tidak punya relasi dulu
+func Models() []interface{} {
+	return []interface{}{
+		&User{},
+		&Wallet{},
+		&Address{},
+		&Product{},
+		&UserLikeProduct{},
+		&Todo{},
+		&GuestBook{},
+		&UserLog{},
+		&LedgerEntry{},
+		&IdempotencyKey{},
+		&UserEmailVerification{},
+		&APIToken{},
+		&Role{},
+		&Permission{},
+		&Tag{},
+		&Order{},
+		&OrderDetail{},
+		&StockReservation{},
+		&Category{},
+	}
+}
@@ -0,0 +1,146 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrStaleObject = errors.New("stale object: row was changed by another update")
+
+// Version -> field untuk optimistic locking, naik satu setiap kali row diupdate.
+// Update dari struct yang Version-nya sudah diketahui (hasil query) menambah WHERE version = ?,
+// jika row sudah diubah orang lain hasilnya ErrStaleObject.
+type Version int64
+
+var versionType = reflect.TypeOf(Version(0))
+
+// OptimisticLock -> plugin gorm untuk field Version, didaftarkan di Connect
+type OptimisticLock struct{}
+
+func (OptimisticLock) Name() string {
+	return "optimistic_lock"
+}
+
+func (OptimisticLock) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("optimistic_lock:create", versionCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("optimistic_lock:before_update", versionBeforeUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register("optimistic_lock:after_update", versionAfterUpdate)
+}
+
+func versionField(stmt *gorm.Statement) *schema.Field {
+	if stmt.Schema == nil {
+		return nil
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == versionType && field.DBName != "" {
+			return field
+		}
+	}
+	return nil
+}
+
+// versionCreate -> row baru selalu mulai dari version 1
+func versionCreate(db *gorm.DB) {
+	field := versionField(db.Statement)
+	if db.Error != nil || field == nil {
+		return
+	}
+	ctx, rv := db.Statement.Context, db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			elem := reflect.Indirect(rv.Index(i))
+			if _, isZero := field.ValueOf(ctx, elem); isZero {
+				db.AddError(field.Set(ctx, elem, Version(1)))
+			}
+		}
+	case reflect.Struct:
+		if _, isZero := field.ValueOf(ctx, rv); isZero {
+			db.AddError(field.Set(ctx, rv, Version(1)))
+		}
+	}
+}
+
+const versionCheckedKey = "optimistic_lock:checked"
+
+// versionBeforeUpdate -> SET version = version + 1, dan WHERE version = ? jika version model diketahui
+func versionBeforeUpdate(db *gorm.DB) {
+	stmt := db.Statement
+	field := versionField(stmt)
+	if db.Error != nil || field == nil || stmt.SQL.Len() != 0 {
+		return
+	}
+	if _, ok := stmt.Clauses["SET"]; ok {
+		return
+	}
+
+	set := callbacks.ConvertToAssignments(stmt)
+	if len(set) == 0 || db.Error != nil {
+		return
+	}
+	assignments := make(clause.Set, 0, len(set)+1)
+	for _, assignment := range set {
+		if assignment.Column.Name != field.DBName {
+			assignments = append(assignments, assignment)
+		}
+	}
+	assignments = append(assignments, clause.Assignment{
+		Column: clause.Column{Name: field.DBName},
+		Value:  gorm.Expr("? + 1", clause.Column{Table: clause.CurrentTable, Name: field.DBName}),
+	})
+	stmt.AddClause(assignments)
+
+	if stmt.ReflectValue.Kind() == reflect.Struct {
+		if version, isZero := field.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+				clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: version},
+			}})
+			db.InstanceSet(versionCheckedKey, version)
+		}
+	}
+}
+
+// versionAfterUpdate -> ErrStaleObject jika tidak ada row yang cocok, selain itu version di struct ikut naik
+func versionAfterUpdate(db *gorm.DB) {
+	stmt := db.Statement
+	field := versionField(stmt)
+	if field == nil {
+		return
+	}
+	delete(stmt.Clauses, "SET")
+
+	checked, ok := db.InstanceGet(versionCheckedKey)
+	if !ok || db.Error != nil || db.DryRun {
+		return
+	}
+	if db.RowsAffected == 0 {
+		db.AddError(fmt.Errorf("%w: %s version %v", ErrStaleObject, stmt.Table, checked))
+		return
+	}
+	db.AddError(field.Set(stmt.Context, stmt.ReflectValue, checked.(Version)+1))
+}
+
+// RetryOnStale -> menjalankan fn (baca, ubah, simpan) lagi selama hasilnya ErrStaleObject, maksimal attempts kali
+func RetryOnStale(ctx context.Context, attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); !errors.Is(err, ErrStaleObject) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+	return err
+}
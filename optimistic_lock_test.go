@@ -0,0 +1,110 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionOnCreate(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	product := Product{Name: "Product Baru", Price: 5000}
+	assert.Nil(t, db.Create(&product).Error)
+	assert.Equal(t, Version(1), product.Version)
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", seed.Wallets["gojo"]).Error)
+	assert.Equal(t, Version(1), wallet.Version)
+}
+
+func TestStaleSave(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	var first, second Product
+	assert.Nil(t, db.Take(&first, "id = ?", seed.Products["product_1"]).Error)
+	assert.Nil(t, db.Take(&second, "id = ?", seed.Products["product_1"]).Error)
+
+	first.Price = 900000
+	assert.Nil(t, db.Save(&first).Error)
+	assert.Equal(t, Version(2), first.Version)
+
+	//second masih membawa version 1
+	second.Price = 800000
+	err := db.Save(&second).Error
+	assert.True(t, errors.Is(err, ErrStaleObject))
+	assert.Equal(t, Version(1), second.Version)
+
+	err = db.Model(&second).Updates(map[string]interface{}{"name": "Product Lama"}).Error
+	assert.True(t, errors.Is(err, ErrStaleObject))
+
+	var product Product
+	assert.Nil(t, db.Take(&product, "id = ?", seed.Products["product_1"]).Error)
+	assert.Equal(t, int64(900000), product.Price)
+	assert.Equal(t, "Contoh Product 1", product.Name)
+	assert.Equal(t, Version(2), product.Version)
+}
+
+func TestVersionWithoutModel(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	//update tanpa version yang diketahui tetap menaikkan version
+	err := db.Model(&Wallet{}).Where("id = ?", seed.Wallets["gojo"]).Update("balance", 500).Error
+	assert.Nil(t, err)
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", seed.Wallets["gojo"]).Error)
+	assert.Equal(t, Version(2), wallet.Version)
+	assert.Equal(t, int64(500), wallet.Balance)
+}
+
+func TestRetryOnStale(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+
+	attempts := 0
+	err := RetryOnStale(ctx, 3, func() error {
+		attempts++
+		var user User
+		if err := db.Take(&user, "id = ?", seed.Users["gojo"]).Error; err != nil {
+			return err
+		}
+		if attempts == 1 {
+			//ada update lain di antara baca dan simpan
+			db.Model(&User{}).Where("id = ?", user.ID).Update("last_name", "Satoru")
+		}
+		user.Name.MiddleName = "Sensei"
+		return db.Save(&user).Error
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+
+	var user User
+	assert.Nil(t, db.Take(&user, "id = ?", seed.Users["gojo"]).Error)
+	assert.Equal(t, "Sensei", user.Name.MiddleName)
+	assert.Equal(t, "Satoru", user.Name.LastName)
+
+	err = RetryOnStale(ctx, 2, func() error { return ErrStaleObject })
+	assert.True(t, errors.Is(err, ErrStaleObject))
+}
+
+func TestUserRepositoryStale(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			user := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}}
+			assert.Nil(t, repo.Create(ctx, &user))
+
+			first, _ := repo.GetByID(ctx, user.ID)
+			second, _ := repo.GetByID(ctx, user.ID)
+			first.Name.LastName = "Satoru"
+			assert.Nil(t, repo.Update(ctx, first))
+			assert.Equal(t, Version(2), first.Version)
+
+			second.Name.LastName = "Geto"
+			assert.True(t, errors.Is(repo.Update(ctx, second), ErrStaleObject))
+		})
+	}
+}
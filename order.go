@@ -0,0 +1,190 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmptyCart       = errors.New("checkout needs at least one item")
+	ErrInvalidQuantity = errors.New("quantity must be positive")
+	ErrProductNotFound = errors.New("product not found")
+	ErrOrderNotFound   = errors.New("order not found")
+)
+
+// status Order
+const (
+	OrderPending   = "pending" //stock sudah di reservasi, belum dibayar
+	OrderPaid      = "paid"
+	OrderCancelled = "cancelled"
+	OrderExpired   = "expired" //reservasi habis waktu sebelum dibayar
+)
+
+// Order -> pesanan user, dibayar dari wallet saat checkout
+type Order struct {
+	ID              int           `gorm:"primary_key;column:id;autoIncrement"`
+	UserId          int           `gorm:"column:user_id;index:idx_orders_user_id"`
+	Status          string        `gorm:"column:status;size:20"`
+	ItemCount       int           `gorm:"column:item_count"`                //jumlah quantity semua detail
+	Total           int64         `gorm:"column:total"`                     //jumlah subtotal semua detail
+	LedgerReference string        `gorm:"column:ledger_reference;size:100"` //reference ledger pembayaran
+	CreatedAt       time.Time     `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time     `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	User            *User         `gorm:"foreignKey:user_id;references:id"`  //relasi belongs to
+	Details         []OrderDetail `gorm:"foreignKey:order_id;references:id"` //relasi one to many
+}
+
+func (o *Order) TableName() string {
+	return "orders"
+}
+
+// OrderDetail -> satu product di dalam Order, harga disimpan saat checkout supaya tidak ikut berubah
+type OrderDetail struct {
+	ID        int64     `gorm:"primary_key;column:id;autoIncrement"`
+	OrderId   int       `gorm:"column:order_id;index:idx_order_details_order_id"`
+	ProductId int       `gorm:"column:product_id"`
+	Quantity  int       `gorm:"column:quantity"`
+	UnitPrice int64     `gorm:"column:unit_price"` //Product.Price saat checkout
+	Subtotal  int64     `gorm:"column:subtotal"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	Product   *Product  `gorm:"foreignKey:product_id;references:id"` //relasi belongs to
+}
+
+func (o *OrderDetail) TableName() string {
+	return "order_details"
+}
+
+// CheckoutItem -> product dan jumlah yang dibeli
+type CheckoutItem struct {
+	ProductID int
+	Quantity  int
+}
+
+// OrderService -> checkout dan query Order
+type OrderService struct {
+	db *gorm.DB
+}
+
+func NewOrderService(db *gorm.DB) *OrderService {
+	return &OrderService{db: db}
+}
+
+// mergeItems -> product yang sama digabung, urut product id
+func mergeItems(items []CheckoutItem) ([]CheckoutItem, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	quantities := map[int]int{}
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: product %d", ErrInvalidQuantity, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	merged := make([]CheckoutItem, 0, len(quantities))
+	for productID, quantity := range quantities {
+		merged = append(merged, CheckoutItem{ProductID: productID, Quantity: quantity})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ProductID < merged[j].ProductID })
+	return merged, nil
+}
+
+// Checkout -> membuat Order dengan harga dari Product.Price, mengurangi stock dan mendebit wallet user dalam satu transaction.
+// Jika ctx punya WithIdempotencyKey, retry dengan key yang sama mengembalikan order yang sudah dibuat.
+func (s *OrderService) Checkout(ctx context.Context, userID int, items []CheckoutItem) (*Order, error) {
+	merged, err := mergeItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	request := struct {
+		Op    string
+		User  int
+		Items []CheckoutItem
+	}{"checkout", userID, merged}
+	order := &Order{}
+	err = idempotentTransaction(ctx, s.db, request, order, func(tx *gorm.DB) error {
+		ids := make([]int, len(merged))
+		for i, item := range merged {
+			ids[i] = item.ProductID
+		}
+		products, err := lockProducts(tx, ids...)
+		if err != nil {
+			return err
+		}
+
+		*order = Order{UserId: userID, Status: OrderPaid}
+		for _, item := range merged {
+			if err := decrementStock(tx, item.ProductID, item.Quantity); err != nil {
+				return err
+			}
+			price := products[item.ProductID].Price
+			detail := OrderDetail{
+				ProductId: item.ProductID,
+				Quantity:  item.Quantity,
+				UnitPrice: price,
+				Subtotal:  price * int64(item.Quantity),
+			}
+			order.Details = append(order.Details, detail)
+			order.ItemCount += detail.Quantity
+			order.Total += detail.Subtotal
+		}
+
+		wallets, err := lockWallets(tx, userID)
+		if err != nil {
+			return err
+		}
+		wallet := wallets[userID]
+		if wallet.Balance < order.Total {
+			return fmt.Errorf("%w: balance %d, total %d", ErrInsufficientFunds, wallet.Balance, order.Total)
+		}
+
+		if order.LedgerReference, err = newReference("order"); err != nil {
+			return err
+		}
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		if order.Total == 0 {
+			return nil
+		}
+		return postLedger(tx, order.LedgerReference,
+			LedgerEntry{WalletId: wallet.ID, Amount: order.Total, Direction: LedgerDebit},
+			LedgerEntry{WalletId: SystemWalletID, Amount: order.Total, Direction: LedgerCredit},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetOrder -> Order beserta detail dan product-nya
+func (s *OrderService) GetOrder(ctx context.Context, id int) (*Order, error) {
+	var order Order
+	err := s.db.WithContext(ctx).Preload("Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Details.Product").Take(&order, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrders -> order milik user, terbaru lebih dulu
+func (s *OrderService) ListOrders(ctx context.Context, userID int, page Page) ([]Order, error) {
+	var orders []Order
+	err := s.db.WithContext(ctx).
+		Scopes(OwnedBy(userID)).
+		Order("id DESC").Limit(page.limit()).Offset(page.offset()).
+		Find(&orders).Error
+	return orders, err
+}
@@ -0,0 +1,96 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckout(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewOrderService(db)
+	wallets := NewWalletService(db)
+	ctx := context.Background()
+
+	order, err := service.Checkout(ctx, seed.Users["nanami"], []CheckoutItem{
+		{ProductID: seed.Products["product_2"], Quantity: 2},
+		{ProductID: seed.Products["product_3"], Quantity: 1},
+		{ProductID: seed.Products["product_2"], Quantity: 1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, OrderPaid, order.Status)
+	assert.Equal(t, 4, order.ItemCount)
+	assert.Equal(t, int64(3*250000+75000), order.Total)
+	assert.Equal(t, 2, len(order.Details))
+	assert.Equal(t, 1000000-order.Total, walletBalance(t, wallets, seed.Users["nanami"]))
+
+	//harga product berubah, detail order tetap memakai harga saat checkout
+	assert.Nil(t, db.Model(&Product{}).Where("id = ?", seed.Products["product_2"]).Update("price", 1).Error)
+	loaded, err := service.GetOrder(ctx, order.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(250000), loaded.Details[0].UnitPrice)
+	assert.Equal(t, int64(750000), loaded.Details[0].Subtotal)
+	assert.Equal(t, "Contoh Product 2", loaded.Details[0].Product.Name)
+
+	//pembayaran tercatat di ledger dengan reference order
+	var entries []LedgerEntry
+	assert.Nil(t, db.Where("reference = ?", order.LedgerReference).Find(&entries).Error)
+	assert.Equal(t, 2, len(entries))
+	rebuilt, err := wallets.RebuildBalance(ctx, seed.Wallets["nanami"])
+	assert.Nil(t, err)
+	assert.Equal(t, 1000000-order.Total, rebuilt)
+
+	orders, err := service.ListOrders(ctx, seed.Users["nanami"], Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(orders))
+}
+
+func TestCheckoutFailures(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewOrderService(db)
+	ctx := context.Background()
+	gojo := seed.Users["gojo"] //saldo 100
+
+	_, err := service.Checkout(ctx, gojo, nil)
+	assert.Equal(t, ErrEmptyCart, err)
+
+	_, err = service.Checkout(ctx, gojo, []CheckoutItem{{ProductID: seed.Products["product_1"], Quantity: 0}})
+	assert.True(t, errors.Is(err, ErrInvalidQuantity))
+
+	_, err = service.Checkout(ctx, gojo, []CheckoutItem{{ProductID: 999, Quantity: 1}})
+	assert.True(t, errors.Is(err, ErrProductNotFound))
+
+	_, err = service.Checkout(ctx, gojo, []CheckoutItem{{ProductID: seed.Products["product_3"], Quantity: 1}})
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+
+	//tidak ada order yang tersimpan dan saldo tidak berubah
+	var count int64
+	assert.Nil(t, db.Model(&Order{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+	assert.Nil(t, db.Model(&OrderDetail{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+	assert.Equal(t, int64(100), walletBalance(t, NewWalletService(db), gojo))
+
+	_, err = service.GetOrder(ctx, 999)
+	assert.Equal(t, ErrOrderNotFound, err)
+}
+
+func TestCheckoutIdempotent(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewOrderService(db)
+	ctx := WithIdempotencyKey(context.Background(), "checkout-1")
+	items := []CheckoutItem{{ProductID: seed.Products["product_3"], Quantity: 2}}
+
+	first, err := service.Checkout(ctx, seed.Users["toji"], items)
+	assert.Nil(t, err)
+	second, err := service.Checkout(ctx, seed.Users["toji"], items)
+	assert.Nil(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, first.Total, second.Total)
+	assert.Equal(t, 1000000-first.Total, walletBalance(t, NewWalletService(db), seed.Users["toji"]))
+
+	_, err = service.Checkout(ctx, seed.Users["toji"], []CheckoutItem{{ProductID: seed.Products["product_3"], Quantity: 3}})
+	assert.True(t, errors.Is(err, ErrIdempotencyConflict))
+}
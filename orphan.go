@@ -0,0 +1,104 @@
+package golanggorm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// OrphanRow -> baris yang user_id-nya kosong, bukan angka, atau tidak ada di tabel users.
+// ID berisi kolom id, untuk user_like_product berisi product_id
+type OrphanRow struct {
+	Table  string `json:"table"`
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+// OrphanError -> migrasi user_id ke integer dibatalkan karena masih ada baris yatim, lihat Rows
+type OrphanError struct {
+	Rows []OrphanRow
+}
+
+func (e *OrphanError) Error() string {
+	counts := map[string]int{}
+	for _, row := range e.Rows {
+		counts[row.Table]++
+	}
+	tables := make([]string, 0, len(counts))
+	for table, count := range counts {
+		tables = append(tables, fmt.Sprintf("%s=%d", table, count))
+	}
+	sort.Strings(tables)
+	return "orphaned user_id rows: " + strings.Join(tables, " ")
+}
+
+// userForeignKey -> tabel yang user_id-nya menjadi foreign key ke users di migrasi convert_user_id_to_integer
+type userForeignKey struct {
+	table string
+	// key -> kolom yang dilaporkan sebagai OrphanRow.ID, kosong berarti id
+	key string
+	// required -> user harus ada, user_logs boleh menunjuk user yang sudah dihapus
+	required bool
+	// integer -> user_id sudah integer sejak awal, hanya foreign key-nya yang ditambahkan
+	integer bool
+}
+
+var userForeignKeys = []userForeignKey{
+	{table: "todos", required: true},
+	{table: "addresses", required: true},
+	{table: "wallets", required: true, integer: true},
+	{table: "user_like_product", key: "product_id", required: true, integer: true},
+	{table: "user_logs"},
+}
+
+// FindOrphans -> laporan baris yang akan membuat migrasi user_id ke integer gagal,
+// bisa dijalankan sebelum migrasi untuk membersihkan data lebih dulu
+func FindOrphans(ctx context.Context, db *gorm.DB) ([]OrphanRow, error) {
+	return findOrphans(db.WithContext(ctx))
+}
+
+func findOrphans(tx *gorm.DB) ([]OrphanRow, error) {
+	var ids []int
+	if err := tx.Table("users").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	users := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		users[id] = true
+	}
+
+	var orphans []OrphanRow
+	for _, fk := range userForeignKeys {
+		var rows []struct {
+			ID     string
+			UserID *string
+		}
+		key := fk.key
+		if key == "" {
+			key = "id"
+		}
+		err := tx.Table(fk.table).Select(key + " AS id, user_id").Order(key).Order("user_id").Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			value := ""
+			if row.UserID != nil {
+				value = *row.UserID
+			}
+			if value == "" && !fk.required {
+				continue
+			}
+			userID, err := strconv.Atoi(value)
+			if err == nil && (users[userID] || !fk.required) {
+				continue
+			}
+			orphans = append(orphans, OrphanRow{Table: fk.table, ID: row.ID, UserID: value})
+		}
+	}
+	return orphans, nil
+}
@@ -0,0 +1,146 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertUserIdOrphans(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	assert.Nil(t, err)
+
+	//kembali ke user_id string (sebelum convert_user_id_to_integer), lalu isi data lama yang yatim
+	migrations := Migrations()
+	for i, migration := range migrations {
+		if migration.Name == "convert_user_id_to_integer" {
+			assert.Nil(t, migrator.Down(ctx, len(migrations)-i))
+		}
+	}
+	assert.False(t, db.Migrator().HasConstraint("todos", "fk_users_todos"))
+	assert.False(t, db.Migrator().HasConstraint("wallets", "fk_users_wallet"))
+	assert.Nil(t, db.Exec("insert into todos (user_id, title) values (?, ?), (?, ?), (?, ?)",
+		"999", "User sudah dihapus", "", "Tanpa user", "1", "Valid").Error)
+	assert.Nil(t, db.Exec("insert into addresses (user_id, address) values (?, ?)", "abc", "Jalan Z").Error)
+	assert.Nil(t, db.Exec("insert into user_logs (user_id, action) values (?, ?), (?, ?), (?, ?)",
+		"x", "login", "", "system", "999", "login").Error)
+	//user_id wallets dan user_like_product sudah integer, tapi foreign key-nya juga ditambahkan migrasi ini
+	assert.Nil(t, db.Exec("insert into wallets (user_id, balance) values (?, ?)", 999, 0).Error)
+	assert.Nil(t, db.Exec("insert into user_like_product (user_id, product_id) values (?, ?)", 999, seed.Products["product_2"]).Error)
+
+	orphans, err := FindOrphans(ctx, db)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(orphans))
+	assert.Equal(t, OrphanRow{Table: "todos", ID: orphans[0].ID, UserID: "999"}, orphans[0])
+	assert.Equal(t, "", orphans[1].UserID)
+	assert.Equal(t, "addresses", orphans[2].Table)
+	assert.Equal(t, OrphanRow{Table: "wallets", ID: orphans[3].ID, UserID: "999"}, orphans[3])
+	assert.Equal(t, OrphanRow{Table: "user_like_product", ID: strconv.Itoa(seed.Products["product_2"]), UserID: "999"}, orphans[4])
+	assert.Equal(t, OrphanRow{Table: "user_logs", ID: orphans[5].ID, UserID: "x"}, orphans[5])
+
+	err = migrator.Up(ctx)
+	var orphanErr *OrphanError
+	assert.True(t, errors.As(err, &orphanErr))
+	assert.Equal(t, orphans, orphanErr.Rows)
+	assert.Contains(t, err.Error(), "addresses=1 todos=2 user_like_product=1 user_logs=1 wallets=1")
+
+	for _, orphan := range orphans {
+		key := "id"
+		if orphan.Table == "user_like_product" {
+			key = "product_id"
+		}
+		assert.Nil(t, db.Exec("delete from "+orphan.Table+" where "+key+" = ? and user_id = ?", orphan.ID, orphan.UserID).Error)
+	}
+	assert.Nil(t, migrator.Up(ctx))
+
+	columnTypes, err := db.Migrator().ColumnTypes(&Todo{})
+	assert.Nil(t, err)
+	for _, columnType := range columnTypes {
+		if columnType.Name() == "user_id" {
+			assert.Equal(t, "integer", strings.ToLower(columnType.DatabaseTypeName()))
+		}
+	}
+	//index lama tetap ada walaupun sqlite membuat ulang tabel
+	for _, index := range []string{"idx_todos_deleted_at", "idx_todos_status", "idx_todos_user_id"} {
+		assert.True(t, db.Migrator().HasIndex(&Todo{}, index), index)
+	}
+	assert.True(t, db.Migrator().HasIndex(&UserLog{}, "idx_user_logs_entity"))
+	assert.True(t, db.Migrator().HasIndex(&UserLikeProduct{}, "idx_user_like_product_product_id"))
+	for table, constraints := range map[string][]string{
+		"todos":             {"fk_users_todos"},
+		"addresses":         {"fk_users_addresses"},
+		"wallets":           {"fk_users_wallet"},
+		"user_like_product": {"fk_user_like_product_user", "fk_user_like_product_product"},
+	} {
+		for _, constraint := range constraints {
+			assert.True(t, db.Migrator().HasConstraint(table, constraint), constraint)
+		}
+	}
+
+	var user User
+	assert.Nil(t, db.Preload("Todos").Take(&user, "id = ?", seed.Users["gojo"]).Error)
+	assert.Equal(t, 1, len(user.Todos))
+	assert.Equal(t, "Valid", user.Todos[0].Title)
+}
+
+func TestUserTodos(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	user := User{ID: seed.Users["nanami"]}
+	todos := []Todo{{Title: "Todo 1"}, {Title: "Todo 2"}}
+	assert.Nil(t, db.Model(&user).Association("Todos").Append(&todos))
+	assert.Equal(t, seed.Users["nanami"], todos[0].UserId)
+
+	var loaded User
+	assert.Nil(t, db.Preload("Todos").Take(&loaded, "id = ?", seed.Users["nanami"]).Error)
+	assert.Equal(t, 2, len(loaded.Todos))
+	assert.Equal(t, seed.Users["nanami"], loaded.Todos[1].OwnerID())
+}
+
+func TestUserForeignKeys(t *testing.T) {
+	db, seed := setupTestDB(t)
+	assert.NotNil(t, db.Create(&Wallet{UserId: 999}).Error)
+	assert.NotNil(t, db.Create(&UserLikeProduct{UserId: seed.Users["gojo"], ProductId: 999}).Error)
+	assert.NotNil(t, db.Create(&UserLikeProduct{UserId: 999, ProductId: seed.Products["product_1"]}).Error)
+
+	//user dihapus, wallet dan like-nya ikut terhapus
+	assert.Nil(t, db.Delete(&User{}, "id = ?", seed.Users["kento"]).Error)
+	var wallets, likes int64
+	assert.Nil(t, db.Model(&Wallet{}).Where("user_id = ?", seed.Users["kento"]).Count(&wallets).Error)
+	assert.Nil(t, db.Model(&UserLikeProduct{}).Where("user_id = ?", seed.Users["kento"]).Count(&likes).Error)
+	assert.Equal(t, int64(0), wallets)
+	assert.Equal(t, int64(0), likes)
+}
+
+func TestConvertUserIdKeepsTodoTags(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	repository := NewTodoRepository(db)
+	todo := &Todo{UserId: seed.Users["gojo"], Title: "Laporan"}
+	assert.Nil(t, repository.Create(ctx, todo))
+	assert.Nil(t, repository.SetTags(ctx, todo.ID, "kantor"))
+
+	//todos dibuat ulang saat down dan up, foreign key aktif tidak boleh membuat todo_tags ikut terhapus
+	migrator, err := NewMigrator(db)
+	assert.Nil(t, err)
+	migrations := Migrations()
+	for i, migration := range migrations {
+		if migration.Name == "convert_user_id_to_integer" {
+			assert.Nil(t, migrator.Down(ctx, len(migrations)-i))
+		}
+	}
+	assert.Nil(t, migrator.Up(ctx))
+
+	var tags int64
+	assert.Nil(t, db.Table("todo_tags").Where("todo_id = ?", todo.ID).Count(&tags).Error)
+	assert.Equal(t, int64(1), tags)
+	var enabled bool
+	assert.Nil(t, db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error)
+	assert.True(t, enabled)
+}
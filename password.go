@@ -0,0 +1,174 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// PasswordCost -> cost bcrypt untuk password baru, hash lama dengan cost berbeda di hash ulang saat login
+var PasswordCost = bcrypt.DefaultCost
+
+// dummyHashes -> hash per cost, dipakai saat user tidak ditemukan supaya waktu respon sama dengan password salah
+var dummyHashes sync.Map
+
+// dummyHash -> dibuat saat pertama dipakai dengan PasswordCost, cost yang sama dengan hash user yang tersimpan
+func dummyHash() []byte {
+	cost := PasswordCost
+	if hash, ok := dummyHashes.Load(cost); ok {
+		return hash.([]byte)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("golang-gorm"), cost)
+	if err != nil {
+		return nil
+	}
+	actual, _ := dummyHashes.LoadOrStore(cost, hash)
+	return actual.([]byte)
+}
+
+// HashPassword -> hash bcrypt dari password plaintext, string kosong dikembalikan apa adanya
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return password, nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// hashPasswordColumn -> hash password di dalam hook BeforeSave, termasuk Update("password", ...) yang memakai map
+func hashPasswordColumn(tx *gorm.DB, u *User) error {
+	switch dest := tx.Statement.Dest.(type) {
+	case map[string]interface{}:
+		for _, column := range []string{"password", "Password"} {
+			if value, ok := dest[column]; ok {
+				hash, err := HashPassword(fmt.Sprint(value))
+				if err != nil {
+					return err
+				}
+				dest[column] = hash
+			}
+		}
+		return nil
+	case *User:
+		//Model(&user).Updates(&User{Password: ...}) -> nilai yang disimpan ada di dest, bukan di model
+		if dest != u {
+			if !dest.passwordChanged() {
+				return nil
+			}
+			hash, err := HashPassword(dest.Password)
+			if err != nil {
+				return err
+			}
+			dest.Password = hash
+			return nil
+		}
+	case User:
+		//Model(&user).Updates(User{Password: ...}) -> dest bukan pointer, SetColumn membuat salinan yang bisa diubah
+		if dest.passwordChanged() {
+			hash, err := HashPassword(dest.Password)
+			if err != nil {
+				return err
+			}
+			tx.Statement.SetColumn("Password", hash)
+		}
+		return nil
+	}
+
+	if !u.passwordChanged() {
+		return nil
+	}
+	hash, err := HashPassword(u.Password)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
+
+// passwordChanged -> Password berbeda dari nilai terakhir yang dibaca/disimpan ke db, berarti plaintext baru.
+// Tidak menebak dari bentuk nilainya, password yang kebetulan mirip hash bcrypt tetap di hash
+func (u *User) passwordChanged() bool {
+	return u.Password != "" && u.Password != u.savedPassword
+}
+
+// AfterFind -> hash yang dibaca dari db dicatat supaya Save tanpa mengubah password tidak meng-hash ulang hash
+func (u *User) AfterFind(tx *gorm.DB) error {
+	u.savedPassword = u.Password
+	return nil
+}
+
+func (u *User) AfterSave(tx *gorm.DB) error {
+	u.savedPassword = u.Password
+	return nil
+}
+
+// CheckPassword -> true jika password cocok dengan hash yang tersimpan
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+}
+
+// needsRehash -> hash dibuat dengan cost yang berbeda dari PasswordCost
+func (u *User) needsRehash() bool {
+	cost, err := bcrypt.Cost([]byte(u.Password))
+	return err == nil && cost != PasswordCost
+}
+
+// AuthService -> login user memakai UserRepository
+type AuthService struct {
+	users UserRepository
+}
+
+func NewAuthService(users UserRepository) *AuthService {
+	return &AuthService{users: users}
+}
+
+// Authenticate -> mencari user dari identifier (email atau id user) dan mencocokkan password.
+// Semua kegagalan (user tidak ada atau password salah) menjadi ErrInvalidCredentials.
+func (s *AuthService) Authenticate(ctx context.Context, identifier, password string) (*User, error) {
+	user, err := s.lookup(ctx, identifier)
+	if errors.Is(err, ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.CheckPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.needsRehash() {
+		hash := user.Password
+		user.Password = password
+		if err := s.users.Update(ctx, user); err != nil {
+			//update tidak tersimpan, hash lama dikembalikan supaya plaintext tidak ikut keluar
+			user.Password = hash
+			if !errors.Is(err, ErrStaleObject) {
+				return nil, err
+			}
+		}
+	}
+	return user, nil
+}
+
+func (s *AuthService) lookup(ctx context.Context, identifier string) (*User, error) {
+	if strings.Contains(identifier, "@") {
+		return s.users.GetByEmail(ctx, identifier)
+	}
+	id, err := strconv.Atoi(identifier)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.users.GetByID(ctx, id)
+}
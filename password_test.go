@@ -0,0 +1,161 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func storedPassword(t *testing.T, repo UserRepository, id int) string {
+	user, err := repo.GetByID(context.Background(), id)
+	assert.Nil(t, err)
+	return user.Password
+}
+
+func TestPasswordNeverStoredPlain(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	//fixture
+	var user User
+	assert.Nil(t, db.Take(&user, "id = ?", seed.Users["gojo"]).Error)
+	assert.NotEqual(t, "rahasia", user.Password)
+	assert.True(t, user.CheckPassword("rahasia"))
+	assert.False(t, user.CheckPassword("salah"))
+
+	//Save tanpa mengubah password, hash tetap
+	hash := user.Password
+	user.Name.LastName = "Satoru"
+	assert.Nil(t, db.Save(&user).Error)
+	assert.Equal(t, hash, user.Password)
+
+	//Save
+	user.Password = "baru"
+	assert.Nil(t, db.Save(&user).Error)
+	assert.True(t, user.CheckPassword("baru"))
+
+	//Update dengan map
+	assert.Nil(t, db.Model(&user).Update("password", "lewat-map").Error)
+	var loaded User
+	assert.Nil(t, db.Take(&loaded, "id = ?", user.ID).Error)
+	assert.True(t, loaded.CheckPassword("lewat-map"))
+
+	//Updates dengan struct yang berbeda dari model
+	assert.Nil(t, db.Model(&loaded).Updates(User{Password: "lewat-struct"}).Error)
+	assert.Nil(t, db.Take(&loaded, "id = ?", user.ID).Error)
+	assert.True(t, loaded.CheckPassword("lewat-struct"))
+
+	var plain int64
+	assert.Nil(t, db.Model(&User{}).Where("password IN ?", []string{"rahasia", "secret", "baru", "lewat-map", "lewat-struct"}).Count(&plain).Error)
+	assert.Equal(t, int64(0), plain)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			auth := NewAuthService(repo)
+			user := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}}
+			assert.Nil(t, repo.Create(ctx, &user))
+			assert.NotEqual(t, "rahasia", storedPassword(t, repo, user.ID))
+
+			found, err := auth.Authenticate(ctx, strconv.Itoa(user.ID), "rahasia")
+			assert.Nil(t, err)
+			assert.Equal(t, user.ID, found.ID)
+
+			_, err = auth.Authenticate(ctx, strconv.Itoa(user.ID), "salah")
+			assert.True(t, errors.Is(err, ErrInvalidCredentials))
+			_, err = auth.Authenticate(ctx, "999999", "rahasia")
+			assert.True(t, errors.Is(err, ErrInvalidCredentials))
+			_, err = auth.Authenticate(ctx, "gojo", "rahasia")
+			assert.True(t, errors.Is(err, ErrInvalidCredentials))
+		})
+	}
+}
+
+func TestAuthenticateRehash(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			user := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}}
+			assert.Nil(t, repo.Create(ctx, &user))
+
+			PasswordCost = bcrypt.MinCost + 1
+			defer func() { PasswordCost = bcrypt.MinCost }()
+
+			_, err := NewAuthService(repo).Authenticate(ctx, strconv.Itoa(user.ID), "rahasia")
+			assert.Nil(t, err)
+
+			cost, err := bcrypt.Cost([]byte(storedPassword(t, repo, user.ID)))
+			assert.Nil(t, err)
+			assert.Equal(t, bcrypt.MinCost+1, cost)
+		})
+	}
+}
+
+func TestDummyHashUsesPasswordCost(t *testing.T) {
+	//user yang tidak ada harus sama lambatnya dengan password salah untuk user yang ada
+	for _, cost := range []int{bcrypt.MinCost, bcrypt.MinCost + 1} {
+		PasswordCost = cost
+		hashCost, err := bcrypt.Cost(dummyHash())
+		assert.Nil(t, err)
+		assert.Equal(t, cost, hashCost)
+	}
+	PasswordCost = bcrypt.MinCost
+}
+
+func TestPasswordShapedLikeHashIsHashed(t *testing.T) {
+	ctx := context.Background()
+	lookalike, err := bcrypt.GenerateFromPassword([]byte("milik orang lain"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			//password yang berbentuk hash bcrypt tetap plaintext bagi user, harus di hash
+			user := User{Password: string(lookalike), Name: Name{FirstName: "Gojo"}}
+			assert.Nil(t, repo.Create(ctx, &user))
+			stored := storedPassword(t, repo, user.ID)
+			assert.NotEqual(t, string(lookalike), stored)
+
+			_, err := NewAuthService(repo).Authenticate(ctx, strconv.Itoa(user.ID), string(lookalike))
+			assert.Nil(t, err)
+			_, err = NewAuthService(repo).Authenticate(ctx, strconv.Itoa(user.ID), "milik orang lain")
+			assert.True(t, errors.Is(err, ErrInvalidCredentials))
+
+			//update tanpa mengubah password tidak meng-hash ulang hash yang tersimpan
+			loaded, err := repo.GetByID(ctx, user.ID)
+			assert.Nil(t, err)
+			loaded.Name.LastName = "Satoru"
+			assert.Nil(t, repo.Update(ctx, loaded))
+			assert.Equal(t, stored, storedPassword(t, repo, user.ID))
+		})
+	}
+}
+
+// staleUserRepository -> Update selalu kalah dari update lain (optimistic lock)
+type staleUserRepository struct {
+	UserRepository
+}
+
+func (r staleUserRepository) Update(ctx context.Context, user *User) error {
+	return ErrStaleObject
+}
+
+func TestAuthenticateRehashStale(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository()
+	user := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}}
+	assert.Nil(t, repo.Create(ctx, &user))
+
+	PasswordCost = bcrypt.MinCost + 1
+	defer func() { PasswordCost = bcrypt.MinCost }()
+
+	//rehash gagal karena stale, login tetap berhasil tanpa membawa plaintext
+	found, err := NewAuthService(staleUserRepository{repo}).Authenticate(ctx, strconv.Itoa(user.ID), "rahasia")
+	assert.Nil(t, err)
+	assert.Equal(t, user.Password, found.Password)
+	assert.True(t, found.CheckPassword("rahasia"))
+}
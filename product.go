@@ -6,9 +6,12 @@ type Product struct {
 	ID           int    	`gorm:"primary_key;column:id"`
 	Name         string    	`gorm:"column:name"`
 	Price        int64     	`gorm:"column:price"`
+	Stock        int       	`gorm:"column:stock;not null;default:0"` //unit yang masih bisa dijual (sudah dikurangi reservasi)
 	CreatedAt    time.Time 	`gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt    time.Time 	`gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Version      Version   	`gorm:"column:version;not null;default:1"` //optimistic locking
 	LikedByUsers []User  	`gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:user_id"`
+	Categories   []Category	`gorm:"many2many:product_categories;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:category_id"`
 }
 
 func (p *Product) TableName() string {
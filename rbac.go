@@ -0,0 +1,174 @@
+package golanggorm
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// scope permission
+const (
+	PermissionOwn = "own" // hanya untuk data milik user sendiri
+	PermissionAny = "any" // untuk semua data
+)
+
+// Role -> kumpulan permission, user bisa punya banyak role (many to many seperti user_like_product)
+type Role struct {
+	ID          int          `gorm:"primary_key;column:id;autoIncrement"`
+	Name        string       `gorm:"column:name;size:100;uniqueIndex"`
+	CreatedAt   time.Time    `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time    `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Permissions []Permission `gorm:"many2many:role_permissions;foreignKey:id;joinForeignKey:role_id;references:id;joinReferences:permission_id"`
+	Users       []User       `gorm:"many2many:user_roles;foreignKey:id;joinForeignKey:role_id;references:id;joinReferences:user_id"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+// Permission -> hak akses, contoh Name "todo:update" dengan Scope "own"
+type Permission struct {
+	ID        int       `gorm:"primary_key;column:id;autoIncrement"`
+	Name      string    `gorm:"column:name;size:100;uniqueIndex:idx_permissions_name_scope"`
+	Scope     string    `gorm:"column:scope;size:10;uniqueIndex:idx_permissions_name_scope"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Roles     []Role    `gorm:"many2many:role_permissions;foreignKey:id;joinForeignKey:permission_id;references:id;joinReferences:role_id"`
+}
+
+func (p *Permission) TableName() string {
+	return "permissions"
+}
+
+// Owned -> data yang punya pemilik (user), dipakai oleh Can untuk permission dengan scope "own"
+type Owned interface {
+	OwnerID() int
+}
+
+// OwnedBy -> scope gorm, hanya baris milik userID (kolom user_id)
+func OwnedBy(userID int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "user_id"},
+			Value:  userID,
+		})
+	}
+}
+
+type permissionCacheContext struct{}
+
+// permissionCache -> permission per user yang sudah dibaca dari database dalam satu request
+type permissionCache struct {
+	mu    sync.Mutex
+	users map[int]map[string][]string
+}
+
+// WithPermissionCache -> Can di dalam ctx ini hanya membaca permission setiap user sekali (satu cache per request)
+func WithPermissionCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, permissionCacheContext{}, &permissionCache{users: map[int]map[string][]string{}})
+}
+
+// Authorizer -> pemeriksaan hak akses berdasarkan role user
+type Authorizer struct {
+	db *gorm.DB
+}
+
+func NewAuthorizer(db *gorm.DB) *Authorizer {
+	return &Authorizer{db: db}
+}
+
+// permissions -> nama permission -> daftar scope milik user dari semua role-nya
+func (a *Authorizer) permissions(ctx context.Context, userID int) (map[string][]string, error) {
+	cache, _ := ctx.Value(permissionCacheContext{}).(*permissionCache)
+	if cache != nil {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		if permissions, ok := cache.users[userID]; ok {
+			return permissions, nil
+		}
+	}
+
+	var rows []Permission
+	err := a.db.WithContext(ctx).Distinct("permissions.name", "permissions.scope").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	permissions := map[string][]string{}
+	for _, row := range rows {
+		permissions[row.Name] = append(permissions[row.Name], row.Scope)
+	}
+
+	if cache != nil {
+		cache.users[userID] = permissions
+	}
+	return permissions, nil
+}
+
+// Can -> true jika user punya permission dengan scope "any", atau scope "own" dan resource milik user.
+// resource nil berarti tidak ada data tertentu (misal membuat data baru), scope apapun cukup.
+// Pointer nil seperti (*Todo)(nil) (misal hasil lookup yang gagal) pemiliknya tidak diketahui, hanya scope "any" yang cukup.
+func (a *Authorizer) Can(ctx context.Context, userID int, permission string, resource Owned) (bool, error) {
+	permissions, err := a.permissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	unknownOwner := isNilOwned(resource)
+	for _, scope := range permissions[permission] {
+		switch {
+		case scope == PermissionAny, resource == nil:
+			return true, nil
+		case scope == PermissionOwn && !unknownOwner && resource.OwnerID() == userID:
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Scope -> scope gorm untuk query daftar data: tanpa filter jika user punya scope "any",
+// hanya milik sendiri jika scope "own", dan tidak ada baris sama sekali jika tidak punya permission
+func (a *Authorizer) Scope(ctx context.Context, userID int, permission string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		permissions, err := a.permissions(ctx, userID)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		scopes := permissions[permission]
+		for _, scope := range scopes {
+			if scope == PermissionAny {
+				return db
+			}
+		}
+		if len(scopes) > 0 {
+			return OwnedBy(userID)(db)
+		}
+		return db.Where("1 = 0")
+	}
+}
+
+// isNilOwned -> interface berisi pointer nil, OwnerID pada pointer nil akan panic
+func isNilOwned(resource Owned) bool {
+	if resource == nil {
+		return false
+	}
+	value := reflect.ValueOf(resource)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// OwnerID -> Todo milik user_id
+func (t *Todo) OwnerID() int {
+	return t.UserId
+}
+
+// OwnerID -> Address milik user_id
+func (a *Address) OwnerID() int {
+	return a.UserId
+}
@@ -0,0 +1,401 @@
+package golanggorm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SchemaReport -> hasil perbandingan struct model dengan database yang sedang berjalan
+type SchemaReport struct {
+	Tables []TableReport `json:"tables"`
+}
+
+// TableReport -> perbedaan untuk satu tabel
+type TableReport struct {
+	Table                string             `json:"table"`
+	Model                string             `json:"model,omitempty"`
+	MissingTable         bool               `json:"missing_table,omitempty"`
+	MissingColumns       []string           `json:"missing_columns,omitempty"`
+	ExtraColumns         []string           `json:"extra_columns,omitempty"`
+	ColumnMismatches     []ColumnMismatch   `json:"column_mismatches,omitempty"`
+	MissingUniqueIndexes []UniqueIndexDrift `json:"missing_unique_indexes,omitempty"`
+	MissingForeignKeys   []ForeignKeyDrift  `json:"missing_foreign_keys,omitempty"`
+}
+
+// ColumnMismatch -> tipe atau nullable kolom berbeda dengan struct tag
+type ColumnMismatch struct {
+	Column           string `json:"column"`
+	ExpectedType     string `json:"expected_type,omitempty"`
+	ActualType       string `json:"actual_type,omitempty"`
+	ExpectedNullable *bool  `json:"expected_nullable,omitempty"`
+	ActualNullable   *bool  `json:"actual_nullable,omitempty"`
+}
+
+// UniqueIndexDrift -> kolom yang seharusnya unique
+type UniqueIndexDrift struct {
+	Columns []string `json:"columns"`
+	Reason  string   `json:"reason"`
+}
+
+// ForeignKeyDrift -> foreign key dari relasi yang belum ada di database
+type ForeignKeyDrift struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	References string   `json:"references"`
+	Relation   string   `json:"relation"`
+}
+
+func (t TableReport) hasDrift() bool {
+	return t.MissingTable || len(t.MissingColumns) > 0 || len(t.ExtraColumns) > 0 || len(t.ColumnMismatches) > 0 ||
+		len(t.MissingUniqueIndexes) > 0 || len(t.MissingForeignKeys) > 0
+}
+
+// HasDrift -> true kalau ada perbedaan di salah satu tabel
+func (r *SchemaReport) HasDrift() bool {
+	for _, table := range r.Tables {
+		if table.hasDrift() {
+			return true
+		}
+	}
+	return false
+}
+
+// Table -> laporan untuk satu tabel, nil kalau tabel tersebut tidak dicek
+func (r *SchemaReport) Table(name string) *TableReport {
+	for i := range r.Tables {
+		if r.Tables[i].Table == name {
+			return &r.Tables[i]
+		}
+	}
+	return nil
+}
+
+// JSON -> laporan dalam bentuk json, hanya tabel yang punya perbedaan
+func (r *SchemaReport) JSON() ([]byte, error) {
+	drifted := SchemaReport{Tables: []TableReport{}}
+	for _, table := range r.Tables {
+		if table.hasDrift() {
+			drifted.Tables = append(drifted.Tables, table)
+		}
+	}
+	return json.MarshalIndent(drifted, "", "  ")
+}
+
+// CheckSchema -> membandingkan model (struct tag gorm) dengan tabel di database.
+// Kalau models kosong, semua Models() akan dicek.
+func CheckSchema(db *gorm.DB, models ...interface{}) (*SchemaReport, error) {
+	if len(models) == 0 {
+		models = Models()
+	}
+
+	checker := schemaChecker{db: db, tables: map[string]*TableReport{}, seenFK: map[string]bool{}, hasOne: map[string][]UniqueIndexDrift{}}
+	if err := checker.collectHasOne(models); err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		if err := checker.check(model); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &SchemaReport{}
+	for _, name := range checker.order {
+		report.Tables = append(report.Tables, *checker.tables[name])
+	}
+	return report, nil
+}
+
+type schemaChecker struct {
+	db     *gorm.DB
+	order  []string
+	tables map[string]*TableReport
+	seenFK map[string]bool
+	hasOne map[string][]UniqueIndexDrift // foreign key relasi one to one, dikelompokkan per tabel pemiliknya
+}
+
+func (c *schemaChecker) collectHasOne(models []interface{}) error {
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: c.db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("parse %T: %w", model, err)
+		}
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			if rel.Type != schema.HasOne {
+				continue
+			}
+			var columns []string
+			for _, ref := range rel.References {
+				if !ref.OwnPrimaryKey {
+					continue
+				}
+				columns = append(columns, ref.ForeignKey.DBName)
+			}
+			if len(columns) > 0 {
+				table := rel.FieldSchema.Table
+				c.hasOne[table] = append(c.hasOne[table], UniqueIndexDrift{
+					Columns: columns,
+					Reason:  "has one relation " + stmt.Schema.Name + "." + rel.Name,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+func (c *schemaChecker) table(name string) *TableReport {
+	if report, ok := c.tables[name]; ok {
+		return report
+	}
+	report := &TableReport{Table: name}
+	c.tables[name] = report
+	c.order = append(c.order, name)
+	return report
+}
+
+func (c *schemaChecker) check(model interface{}) error {
+	stmt := &gorm.Statement{DB: c.db}
+	if err := stmt.Parse(model); err != nil {
+		return fmt.Errorf("parse %T: %w", model, err)
+	}
+	s := stmt.Schema
+	report := c.table(s.Table)
+	report.Model = s.Name
+
+	if !c.db.Migrator().HasTable(s.Table) {
+		report.MissingTable = true
+		return nil
+	}
+
+	columnTypes, err := c.db.Migrator().ColumnTypes(model)
+	if err != nil {
+		return fmt.Errorf("column types %s: %w", s.Table, err)
+	}
+	actual := map[string]gorm.ColumnType{}
+	for _, columnType := range columnTypes {
+		actual[columnType.Name()] = columnType
+	}
+
+	for _, dbName := range s.DBNames {
+		field := s.FieldsByDBName[dbName]
+		if field.IgnoreMigration {
+			continue
+		}
+		columnType, ok := actual[dbName]
+		if !ok {
+			report.MissingColumns = append(report.MissingColumns, dbName)
+			continue
+		}
+		if mismatch, ok := compareColumn(field, columnType); ok {
+			report.ColumnMismatches = append(report.ColumnMismatches, mismatch)
+		}
+	}
+	for name := range actual {
+		if field := s.LookUpField(name); field == nil || field.DBName == "" || field.IgnoreMigration {
+			report.ExtraColumns = append(report.ExtraColumns, name)
+		}
+	}
+	sort.Strings(report.ExtraColumns)
+
+	unique, err := uniqueColumnSets(c.db, s.Table, columnTypes)
+	if err != nil {
+		return err
+	}
+	for _, expected := range expectedUniqueIndexes(s, c.hasOne[s.Table]) {
+		if !unique[strings.Join(expected.Columns, ",")] {
+			report.MissingUniqueIndexes = append(report.MissingUniqueIndexes, expected)
+		}
+	}
+
+	return c.checkRelations(model, s)
+}
+
+func (c *schemaChecker) checkRelations(model interface{}, s *schema.Schema) error {
+	for _, rel := range s.Relationships.Relations {
+		if rel.Type == schema.Many2Many {
+			join := c.table(rel.JoinTable.Table)
+			if !c.db.Migrator().HasTable(rel.JoinTable.Table) {
+				join.MissingTable = true
+			}
+			continue
+		}
+
+		constraint := rel.ParseConstraint()
+		if constraint == nil || len(constraint.ForeignKeys) == 0 {
+			continue
+		}
+		owner := constraint.Schema.Table
+		key := owner + "." + constraint.Name
+		if c.seenFK[key] || !c.db.Migrator().HasTable(owner) {
+			continue
+		}
+		c.seenFK[key] = true
+
+		if c.db.Migrator().HasConstraint(model, constraint.Name) {
+			continue
+		}
+		drift := ForeignKeyDrift{
+			Name:     constraint.Name,
+			Relation: s.Name + "." + rel.Name,
+		}
+		for _, field := range constraint.ForeignKeys {
+			drift.Columns = append(drift.Columns, field.DBName)
+		}
+		var references []string
+		for _, field := range constraint.References {
+			references = append(references, field.DBName)
+		}
+		drift.References = constraint.ReferenceSchema.Table + "(" + strings.Join(references, ",") + ")"
+
+		report := c.table(owner)
+		report.MissingForeignKeys = append(report.MissingForeignKeys, drift)
+	}
+	return nil
+}
+
+// expectedUniqueIndexes -> unique dari tag (unique/uniqueIndex) ditambah foreign key relasi has one
+func expectedUniqueIndexes(s *schema.Schema, hasOne []UniqueIndexDrift) []UniqueIndexDrift {
+	var result []UniqueIndexDrift
+	seen := map[string]bool{}
+	add := func(columns []string, reason string) {
+		key := strings.Join(columns, ",")
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, UniqueIndexDrift{Columns: columns, Reason: reason})
+		}
+	}
+
+	for _, dbName := range s.DBNames {
+		field := s.FieldsByDBName[dbName]
+		if field.Unique && !field.PrimaryKey {
+			add([]string{dbName}, "unique tag on "+s.Name+"."+field.Name)
+		}
+	}
+	for _, index := range s.ParseIndexes() {
+		if index.Class == "UNIQUE" {
+			var columns []string
+			for _, option := range index.Fields {
+				columns = append(columns, option.DBName)
+			}
+			add(columns, "uniqueIndex "+index.Name)
+		}
+	}
+
+	for _, expected := range hasOne {
+		add(expected.Columns, expected.Reason)
+	}
+	return result
+}
+
+func compareColumn(field *schema.Field, columnType gorm.ColumnType) (ColumnMismatch, bool) {
+	mismatch := ColumnMismatch{Column: field.DBName}
+	drift := false
+
+	expected := typeFamilyOfField(field)
+	actualName := strings.ToLower(columnType.DatabaseTypeName())
+	if expected != "" && actualName != "" && !familyAccepts(expected, typeFamilyOfDatabase(actualName)) {
+		mismatch.ExpectedType = expected
+		mismatch.ActualType = actualName
+		drift = true
+	}
+
+	if !field.PrimaryKey {
+		if nullable, ok := columnType.Nullable(); ok {
+			expectedNullable := !field.NotNull
+			if nullable != expectedNullable {
+				mismatch.ExpectedNullable = &expectedNullable
+				mismatch.ActualNullable = &nullable
+				drift = true
+			}
+		}
+	}
+	return mismatch, drift
+}
+
+func typeFamilyOfField(field *schema.Field) string {
+	switch field.DataType {
+	case schema.Bool:
+		return "bool"
+	case schema.Int, schema.Uint:
+		return "int"
+	case schema.Float:
+		return "float"
+	case schema.String:
+		return "string"
+	case schema.Time:
+		return "time"
+	case schema.Bytes:
+		return "bytes"
+	}
+	return "" //tipe custom tidak dicek
+}
+
+func typeFamilyOfDatabase(name string) string {
+	switch {
+	case strings.Contains(name, "bool"):
+		return "bool"
+	case strings.Contains(name, "int"), name == "serial", name == "bigserial":
+		return "int"
+	case strings.Contains(name, "char"), strings.Contains(name, "text"), strings.Contains(name, "clob"), name == "string", name == "uuid", name == "json", name == "jsonb":
+		return "string"
+	case strings.Contains(name, "real"), strings.Contains(name, "floa"), strings.Contains(name, "doub"), strings.Contains(name, "dec"), strings.Contains(name, "numeric"):
+		return "float"
+	case strings.Contains(name, "date"), strings.Contains(name, "time"):
+		return "time"
+	case strings.Contains(name, "blob"), strings.Contains(name, "binary"), name == "bytea":
+		return "bytes"
+	}
+	return name
+}
+
+func familyAccepts(expected, actual string) bool {
+	//mysql menyimpan bool sebagai tinyint
+	return expected == actual || expected == "bool" && actual == "int"
+}
+
+// uniqueColumnSets -> set kolom (dipisah koma) yang sudah punya unique index/constraint
+func uniqueColumnSets(db *gorm.DB, table string, columnTypes []gorm.ColumnType) (map[string]bool, error) {
+	result := map[string]bool{}
+	for _, columnType := range columnTypes {
+		if unique, ok := columnType.Unique(); ok && unique {
+			result[columnType.Name()] = true
+		}
+	}
+
+	if db.Dialector.Name() == "sqlite" {
+		//driver sqlite belum mendukung GetIndexes, jadi baca langsung dari pragma
+		var indexes []struct {
+			Name   string
+			Unique bool
+		}
+		if err := db.Raw("select name, \"unique\" from pragma_index_list(?)", table).Scan(&indexes).Error; err != nil {
+			return nil, err
+		}
+		for _, index := range indexes {
+			if !index.Unique {
+				continue
+			}
+			var columns []string
+			if err := db.Raw("select name from pragma_index_info(?) order by seqno", index.Name).Scan(&columns).Error; err != nil {
+				return nil, err
+			}
+			result[strings.Join(columns, ",")] = true
+		}
+		return result, nil
+	}
+
+	indexes, err := db.Migrator().GetIndexes(table)
+	if err != nil {
+		return nil, fmt.Errorf("indexes %s: %w", table, err)
+	}
+	for _, index := range indexes {
+		if unique, ok := index.Unique(); ok && unique {
+			result[strings.Join(index.Columns(), ",")] = true
+		}
+	}
+	return result, nil
+}
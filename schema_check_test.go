@@ -0,0 +1,92 @@
+package golanggorm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// database yang baru dimigrasi harus sama persis dengan semua Models()
+func TestCheckSchemaFreshDatabase(t *testing.T) {
+	db, _ := setupTestDB(t)
+
+	report, err := CheckSchema(db)
+	assert.Nil(t, err)
+	assert.False(t, report.HasDrift())
+	for _, table := range report.Tables {
+		assert.Empty(t, table.MissingForeignKeys, table.Table)
+		assert.Empty(t, table.MissingUniqueIndexes, table.Table)
+	}
+}
+
+func TestCheckSchemaWalletUniqueUserID(t *testing.T) {
+	db, _ := setupTestDB(t)
+
+	report, err := CheckSchema(db, &User{}, &Wallet{})
+	assert.Nil(t, err)
+	assert.False(t, report.HasDrift())
+
+	users := report.Table("users")
+	assert.NotNil(t, users)
+	assert.Empty(t, users.MissingColumns)
+	assert.Empty(t, users.ExtraColumns)
+
+	//tanpa unique index, wallets.user_id dilaporkan karena relasi User.Wallet adalah has one
+	assert.Nil(t, db.Migrator().DropIndex(&Wallet{}, "idx_wallets_user_id"))
+	report, err = CheckSchema(db, &User{}, &Wallet{})
+	assert.Nil(t, err)
+	assert.True(t, report.HasDrift())
+	wallets := report.Table("wallets")
+	assert.Equal(t, 1, len(wallets.MissingUniqueIndexes))
+	assert.Equal(t, []string{"user_id"}, wallets.MissingUniqueIndexes[0].Columns)
+	assert.Empty(t, wallets.MissingForeignKeys) //fk_users_wallet dibuat migrasi convert_user_id_to_integer
+
+	//foreign key yang hilang dilaporkan dengan nama dari relasi User.Wallet
+	assert.Nil(t, db.Migrator().DropConstraint("wallets", "fk_users_wallet"))
+	report, err = CheckSchema(db, &User{}, &Wallet{})
+	assert.Nil(t, err)
+	wallets = report.Table("wallets")
+	assert.Equal(t, 1, len(wallets.MissingForeignKeys))
+	assert.Equal(t, "fk_users_wallet", wallets.MissingForeignKeys[0].Name)
+	assert.Equal(t, "users(id)", wallets.MissingForeignKeys[0].References)
+}
+
+func TestCheckSchemaColumns(t *testing.T) {
+	db, _ := setupTestDB(t)
+
+	assert.Nil(t, db.Migrator().DropColumn(&User{}, "middle_name"))
+	assert.Nil(t, db.Exec("alter table users add column nickname varchar(100)").Error)
+	assert.Nil(t, db.Migrator().DropTable(&GuestBook{}))
+
+	report, err := CheckSchema(db)
+	assert.Nil(t, err)
+
+	users := report.Table("users")
+	assert.Equal(t, []string{"middle_name"}, users.MissingColumns)
+	assert.Equal(t, []string{"nickname"}, users.ExtraColumns)
+	assert.True(t, report.Table("guest_books").MissingTable)
+	assert.False(t, report.Table("user_like_product").MissingTable)
+
+	addresses := report.Table("addresses")
+	assert.Empty(t, addresses.ColumnMismatches) //user_id sudah integer sejak migrasi convert_user_id_to_integer
+}
+
+func TestCheckSchemaJSON(t *testing.T) {
+	db, _ := setupTestDB(t)
+	assert.Nil(t, db.Migrator().DropIndex(&Wallet{}, "idx_wallets_user_id"))
+
+	report, err := CheckSchema(db)
+	assert.Nil(t, err)
+
+	data, err := report.JSON()
+	assert.Nil(t, err)
+
+	var decoded SchemaReport
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	for _, table := range decoded.Tables {
+		assert.True(t, table.hasDrift()) //json hanya berisi tabel yang berbeda
+	}
+	assert.NotNil(t, decoded.Table("wallets"))
+	assert.Nil(t, decoded.Table("users"))
+}
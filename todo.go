@@ -1,15 +1,24 @@
 package golanggorm
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Todo struct {
 	// ID			int64			`gorm:"primary_key;column:id;autoIncrement"`
 	gorm.Model
-	UserId		string			`gorm:"column:user_id"`
+	UserId		int				`gorm:"column:user_id;index:idx_todos_user_id"`
 	Title		string			`gorm:"column:title"`
 	Description	string			`gorm:"column:description"`
+	ParentID	*uint			`gorm:"column:parent_id;index:idx_todos_parent_id"` //subtask dari todo lain
+	Subtasks	[]Todo			`gorm:"foreignKey:ParentID;references:ID"` //relasi one to many ke dirinya sendiri
+	Status		string			`gorm:"column:status;size:20;not null;default:open;index:idx_todos_status"` //lihat TodoOpen, TodoInProgress, TodoDone
+	Priority	int				`gorm:"column:priority;not null;default:2"` //lihat PriorityLow, PriorityMedium, PriorityHigh
+	DueAt		*time.Time		`gorm:"column:due_at;index:idx_todos_due_at"`
+	CompletedAt	*time.Time		`gorm:"column:completed_at"` //diisi saat status menjadi done
+	Tags		[]Tag			`gorm:"many2many:todo_tags;foreignKey:ID;joinForeignKey:todo_id;references:ID;joinReferences:tag_id"`
 	// CreatedAt	time.Time		`gorm:"column:created_at;autoCreateTime"`
 	// UpdatedAt	time.Time		`gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 	// DeletedAt	gorm.DeletedAt	`gorm:"column:deleted_at"`
@@ -0,0 +1,185 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTodoNotFound      = errors.New("todo not found")
+	ErrTodoParentDeleted = errors.New("todo parent is deleted")
+)
+
+const defaultPurgeBatchSize = 500
+
+// TodoRepository -> akses data Todo termasuk siklus soft delete: Delete -> Restore atau PurgeOlderThan
+type TodoRepository interface {
+	GetByID(ctx context.Context, id uint) (*Todo, error)
+	Create(ctx context.Context, todo *Todo) error
+	// Delete -> soft delete todo beserta semua subtask-nya
+	Delete(ctx context.Context, id uint) error
+	// Restore -> mengembalikan todo dan subtask yang ikut terhapus bersamanya
+	Restore(ctx context.Context, id uint) error
+	// ListTrashed -> todo milik user yang sudah di soft delete, terbaru lebih dulu
+	ListTrashed(ctx context.Context, userID int) ([]Todo, error)
+	// PurgeOlderThan -> hard delete todo yang sudah di soft delete lebih lama dari age, mengembalikan jumlah baris
+	PurgeOlderThan(ctx context.Context, age time.Duration) (int64, error)
+	// SetStatus -> pindah status sesuai Todo.Transition, mengembalikan todo yang sudah diubah
+	SetStatus(ctx context.Context, id uint, status string) (*Todo, error)
+	// SetTags -> mengganti semua tag todo, tag yang belum ada dibuat
+	SetTags(ctx context.Context, id uint, names ...string) error
+	// Overdue -> todo milik user yang lewat due date dan belum done, paling lama lebih dulu
+	Overdue(ctx context.Context, userID int) ([]Todo, error)
+	// ByTag -> todo milik user dengan tag, prioritas tinggi lebih dulu
+	ByTag(ctx context.Context, userID int, tag string) ([]Todo, error)
+	// CountByStatus -> jumlah todo milik user per status
+	CountByStatus(ctx context.Context, userID int) (map[string]int64, error)
+}
+
+type todoRepository struct {
+	db        *gorm.DB
+	now       func() time.Time
+	batchSize int
+}
+
+// NewTodoRepository -> TodoRepository yang memakai gorm
+func NewTodoRepository(db *gorm.DB) TodoRepository {
+	return &todoRepository{db: db, now: time.Now, batchSize: defaultPurgeBatchSize}
+}
+
+func (r *todoRepository) GetByID(ctx context.Context, id uint) (*Todo, error) {
+	var todo Todo
+	err := r.db.WithContext(ctx).Take(&todo, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTodoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+func (r *todoRepository) Create(ctx context.Context, todo *Todo) error {
+	return r.db.WithContext(ctx).Create(todo).Error
+}
+
+func (r *todoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo Todo
+		err := tx.Take(&todo, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTodoNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		ids, err := todoDescendants(tx, []uint{todo.ID}, func(*Todo) bool { return true })
+		if err != nil {
+			return err
+		}
+		//satu statement, semua baris mendapat deleted_at yang sama sehingga bisa di restore bersama
+		return tx.Where("id IN ?", append(ids, todo.ID)).Delete(&Todo{}).Error
+	})
+}
+
+func (r *todoRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo Todo
+		err := tx.Unscoped().Take(&todo, "id = ? AND deleted_at IS NOT NULL", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTodoNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if todo.ParentID != nil {
+			var count int64
+			if err := tx.Model(&Todo{}).Where("id = ?", *todo.ParentID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrTodoParentDeleted
+			}
+		}
+
+		//subtask yang sudah dihapus sebelum parent-nya tetap di tempat sampah
+		deletedAt := todo.DeletedAt.Time
+		ids, err := todoDescendants(tx.Unscoped().Where("deleted_at IS NOT NULL"), []uint{todo.ID}, func(child *Todo) bool {
+			return child.DeletedAt.Time.Equal(deletedAt)
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&Todo{}).Where("id IN ?", append(ids, todo.ID)).Update("deleted_at", nil).Error
+	})
+}
+
+// todoDescendants -> id semua subtask (rekursif) dari parents yang lolos filter
+func todoDescendants(db *gorm.DB, parents []uint, filter func(*Todo) bool) ([]uint, error) {
+	var ids []uint
+	for len(parents) > 0 {
+		var children []Todo
+		if err := db.Session(&gorm.Session{}).Where("parent_id IN ?", parents).Find(&children).Error; err != nil {
+			return nil, err
+		}
+		parents = parents[:0:0]
+		for i := range children {
+			if filter(&children[i]) {
+				parents = append(parents, children[i].ID)
+			}
+		}
+		ids = append(ids, parents...)
+	}
+	return ids, nil
+}
+
+func (r *todoRepository) ListTrashed(ctx context.Context, userID int) ([]Todo, error) {
+	var todos []Todo
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Scopes(OwnedBy(userID)).
+		Order("deleted_at DESC").Order("id").
+		Find(&todos).Error
+	return todos, err
+}
+
+func (r *todoRepository) PurgeOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	cutoff := r.now().Add(-age)
+	var purged int64
+	for {
+		var ids []uint
+		err := r.db.WithContext(ctx).Unscoped().Model(&Todo{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Order("id").Limit(r.batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		//setiap batch transaksi sendiri supaya lock tidak ditahan terlalu lama,
+		//todo_tags dihapus lebih dulu karena foreign key tidak selalu ditegakkan (sqlite tanpa PRAGMA foreign_keys)
+		var deleted int64
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("DELETE FROM todo_tags WHERE todo_id IN ?", ids).Error; err != nil {
+				return err
+			}
+			result := tx.Unscoped().Where("id IN ?", ids).Delete(&Todo{})
+			deleted = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged += deleted
+		if len(ids) < r.batchSize {
+			return purged, nil
+		}
+	}
+}
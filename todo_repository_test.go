@@ -0,0 +1,136 @@
+package golanggorm
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// createTodoTree -> todo dengan satu subtask yang punya satu subtask lagi
+func createTodoTree(t *testing.T, repository TodoRepository, userID int) (*Todo, *Todo, *Todo) {
+	ctx := context.Background()
+	parent := &Todo{UserId: userID, Title: "Pindahan"}
+	assert.Nil(t, repository.Create(ctx, parent))
+	child := &Todo{UserId: userID, Title: "Packing", ParentID: &parent.ID}
+	assert.Nil(t, repository.Create(ctx, child))
+	grandchild := &Todo{UserId: userID, Title: "Beli kardus", ParentID: &child.ID}
+	assert.Nil(t, repository.Create(ctx, grandchild))
+	return parent, child, grandchild
+}
+
+func TestTodoDeleteCascade(t *testing.T) {
+	db, seed := setupTestDB(t)
+	repository := NewTodoRepository(db)
+	ctx := context.Background()
+	parent, child, grandchild := createTodoTree(t, repository, seed.Users["gojo"])
+
+	assert.Nil(t, repository.Delete(ctx, parent.ID))
+	for _, todo := range []*Todo{parent, child, grandchild} {
+		_, err := repository.GetByID(ctx, todo.ID)
+		assert.Equal(t, ErrTodoNotFound, err)
+	}
+
+	trashed, err := repository.ListTrashed(ctx, seed.Users["gojo"])
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(trashed))
+
+	trashed, err = repository.ListTrashed(ctx, seed.Users["nanami"])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(trashed))
+
+	assert.Equal(t, ErrTodoNotFound, repository.Delete(ctx, parent.ID))
+}
+
+func TestTodoRestoreCascade(t *testing.T) {
+	db, seed := setupTestDB(t)
+	repository := NewTodoRepository(db)
+	ctx := context.Background()
+	parent, child, grandchild := createTodoTree(t, repository, seed.Users["gojo"])
+
+	//grandchild dihapus lebih dulu, tidak ikut di restore bersama parent
+	assert.Nil(t, repository.Delete(ctx, grandchild.ID))
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, repository.Delete(ctx, parent.ID))
+
+	assert.Equal(t, ErrTodoParentDeleted, repository.Restore(ctx, child.ID))
+	assert.Nil(t, repository.Restore(ctx, parent.ID))
+
+	_, err := repository.GetByID(ctx, parent.ID)
+	assert.Nil(t, err)
+	_, err = repository.GetByID(ctx, child.ID)
+	assert.Nil(t, err)
+	_, err = repository.GetByID(ctx, grandchild.ID)
+	assert.Equal(t, ErrTodoNotFound, err)
+
+	assert.Nil(t, repository.Restore(ctx, grandchild.ID))
+	assert.Equal(t, ErrTodoNotFound, repository.Restore(ctx, grandchild.ID))
+}
+
+func TestTodoPurgeOlderThan(t *testing.T) {
+	db, seed := setupTestDB(t)
+	repository := &todoRepository{db: db, now: time.Now, batchSize: 2}
+	ctx := context.Background()
+
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		todo := &Todo{UserId: seed.Users["toji"], Title: "Todo " + strconv.Itoa(i)}
+		assert.Nil(t, repository.Create(ctx, todo))
+		ids = append(ids, todo.ID)
+	}
+	for _, id := range ids {
+		assert.Nil(t, repository.SetTags(ctx, id, "kantor"))
+	}
+	for _, id := range ids[:4] {
+		assert.Nil(t, repository.Delete(ctx, id))
+	}
+	//tiga todo sudah lama di tempat sampah
+	old := time.Now().Add(-48 * time.Hour)
+	assert.Nil(t, db.Unscoped().Model(&Todo{}).Where("id IN ?", ids[:3]).UpdateColumn("deleted_at", old).Error)
+
+	var deletes int
+	assert.Nil(t, db.Callback().Delete().Before("gorm:delete").Register("test:count", func(tx *gorm.DB) {
+		if tx.Statement.Table == "todos" && tx.Statement.Unscoped {
+			deletes++
+		}
+	}))
+
+	purged, err := repository.PurgeOlderThan(ctx, 24*time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.Equal(t, 2, deletes) //batch 2 + 1
+
+	var count int64
+	assert.Nil(t, db.Unscoped().Model(&Todo{}).Where("id IN ?", ids).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	//tag todo yang di purge ikut terhapus, tag todo lain tetap
+	var tagged []uint
+	assert.Nil(t, db.Table("todo_tags").Order("todo_id").Pluck("todo_id", &tagged).Error)
+	assert.Equal(t, ids[3:], tagged)
+
+	purged, err = repository.PurgeOlderThan(ctx, 24*time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), purged)
+}
+
+func TestTodoTagsForeignKeyCascade(t *testing.T) {
+	db, seed := setupTestDB(t)
+	repository := NewTodoRepository(db)
+	ctx := context.Background()
+	assert.True(t, db.Migrator().HasConstraint("todo_tags", "fk_todo_tags_todo"))
+	assert.True(t, db.Migrator().HasConstraint("todo_tags", "fk_todo_tags_tag"))
+
+	todo := &Todo{UserId: seed.Users["toji"], Title: "Laporan"}
+	assert.Nil(t, repository.Create(ctx, todo))
+	assert.Nil(t, repository.SetTags(ctx, todo.ID, "kantor", "penting"))
+
+	assert.NotNil(t, db.Exec("INSERT INTO todo_tags (todo_id, tag_id) VALUES (?, ?)", 999, 999).Error)
+	assert.Nil(t, db.Unscoped().Delete(&Todo{}, "id = ?", todo.ID).Error)
+	var count int64
+	assert.Nil(t, db.Table("todo_tags").Where("todo_id = ?", todo.ID).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
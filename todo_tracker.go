@@ -0,0 +1,212 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// status Todo
+const (
+	TodoOpen       = "open"
+	TodoInProgress = "in_progress"
+	TodoDone       = "done"
+)
+
+// prioritas Todo, semakin besar semakin penting
+const (
+	PriorityLow    = 1
+	PriorityMedium = 2
+	PriorityHigh   = 3
+)
+
+var (
+	ErrInvalidStatus           = errors.New("invalid todo status")
+	ErrInvalidStatusTransition = errors.New("invalid todo status transition")
+	ErrInvalidPriority         = errors.New("invalid todo priority")
+)
+
+// todoTransitions -> status tujuan yang boleh dari setiap status
+var todoTransitions = map[string][]string{
+	TodoOpen:       {TodoInProgress, TodoDone},
+	TodoInProgress: {TodoOpen, TodoDone},
+	TodoDone:       {TodoOpen}, //reopen
+}
+
+// Tag -> label todo, satu tag bisa dipakai banyak todo (many to many)
+type Tag struct {
+	ID        uint      `gorm:"primaryKey;column:id;autoIncrement"`
+	Name      string    `gorm:"column:name;size:50;uniqueIndex:idx_tags_name"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	Todos     []Todo    `gorm:"many2many:todo_tags;foreignKey:ID;joinForeignKey:tag_id;references:ID;joinReferences:todo_id"`
+}
+
+func (t *Tag) TableName() string {
+	return "tags"
+}
+
+// normalizeTag -> nama tag disimpan lowercase tanpa spasi di awal/akhir
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (t *Tag) BeforeSave(tx *gorm.DB) error {
+	t.Name = normalizeTag(t.Name)
+	if t.Name == "" {
+		return errors.New("tag name is required")
+	}
+	return nil
+}
+
+func (t *Todo) BeforeCreate(tx *gorm.DB) error {
+	if t.Status == "" {
+		t.Status = TodoOpen
+	}
+	if t.Priority == 0 {
+		t.Priority = PriorityMedium
+	}
+	return nil
+}
+
+// BeforeSave -> status dan priority harus valid, field kosong (update sebagian) tidak dicek
+func (t *Todo) BeforeSave(tx *gorm.DB) error {
+	if _, ok := todoTransitions[t.Status]; t.Status != "" && !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidStatus, t.Status)
+	}
+	if t.Priority != 0 && (t.Priority < PriorityLow || t.Priority > PriorityHigh) {
+		return fmt.Errorf("%w: %d", ErrInvalidPriority, t.Priority)
+	}
+	return nil
+}
+
+// Transition -> mengubah status jika diizinkan, CompletedAt diisi saat done dan dikosongkan saat dibuka lagi
+func (t *Todo) Transition(status string, now time.Time) error {
+	if _, ok := todoTransitions[status]; !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidStatus, status)
+	}
+	current := t.Status
+	if current == "" {
+		current = TodoOpen
+	}
+	if current == status {
+		return nil
+	}
+	allowed := false
+	for _, next := range todoTransitions[current] {
+		allowed = allowed || next == status
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, current, status)
+	}
+
+	t.Status = status
+	if status == TodoDone {
+		t.CompletedAt = &now
+	} else {
+		t.CompletedAt = nil
+	}
+	return nil
+}
+
+// IsOverdue -> due date sudah lewat dan belum selesai
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.DueAt.Before(now) && t.Status != TodoDone
+}
+
+func (r *todoRepository) SetStatus(ctx context.Context, id uint, status string) (*Todo, error) {
+	var todo Todo
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&todo, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTodoNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := todo.Transition(status, r.now()); err != nil {
+			return err
+		}
+		return tx.Model(&todo).Select("status", "completed_at").Updates(&todo).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+func (r *todoRepository) SetTags(ctx context.Context, id uint, names ...string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo Todo
+		err := tx.Take(&todo, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTodoNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		tags := make([]Tag, 0, len(names))
+		seen := map[string]bool{}
+		for _, name := range names {
+			name = normalizeTag(name)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			tag := Tag{Name: name}
+			if err := tx.Where(Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+		}
+		return tx.Model(&todo).Association("Tags").Replace(tags)
+	})
+}
+
+func (r *todoRepository) Overdue(ctx context.Context, userID int) ([]Todo, error) {
+	var todos []Todo
+	err := r.db.WithContext(ctx).
+		Scopes(OwnedBy(userID)).
+		Where("due_at < ? AND status <> ?", r.now(), TodoDone).
+		Order("due_at").Order("priority DESC").
+		Find(&todos).Error
+	return todos, err
+}
+
+func (r *todoRepository) ByTag(ctx context.Context, userID int, tag string) ([]Todo, error) {
+	var todos []Todo
+	err := r.db.WithContext(ctx).
+		Scopes(OwnedBy(userID)).
+		Joins("JOIN todo_tags ON todo_tags.todo_id = todos.id").
+		Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+		Where("tags.name = ?", normalizeTag(tag)).
+		Preload("Tags").
+		Order("priority DESC").Order("todos.id").
+		Find(&todos).Error
+	return todos, err
+}
+
+func (r *todoRepository) CountByStatus(ctx context.Context, userID int) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Total  int64
+	}
+	err := r.db.WithContext(ctx).Model(&Todo{}).
+		Scopes(OwnedBy(userID)).
+		Select("status, COUNT(*) AS total").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{TodoOpen: 0, TodoInProgress: 0, TodoDone: 0}
+	for _, row := range rows {
+		counts[row.Status] = row.Total
+	}
+	return counts, nil
+}
@@ -0,0 +1,125 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoTransition(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		from, to string
+		err      error
+	}{
+		{TodoOpen, TodoInProgress, nil},
+		{TodoOpen, TodoDone, nil},
+		{TodoInProgress, TodoOpen, nil},
+		{TodoInProgress, TodoDone, nil},
+		{TodoDone, TodoOpen, nil},
+		{TodoDone, TodoInProgress, ErrInvalidStatusTransition},
+		{TodoDone, TodoDone, nil},
+		{TodoOpen, "archived", ErrInvalidStatus},
+	}
+	for _, test := range tests {
+		todo := Todo{Status: test.from}
+		err := todo.Transition(test.to, now)
+		assert.True(t, errors.Is(err, test.err), "%s -> %s: %v", test.from, test.to, err)
+	}
+
+	todo := Todo{Status: TodoInProgress}
+	assert.Nil(t, todo.Transition(TodoDone, now))
+	assert.Equal(t, now, *todo.CompletedAt)
+	assert.Nil(t, todo.Transition(TodoOpen, now))
+	assert.Nil(t, todo.CompletedAt)
+}
+
+func TestTodoDefaultsAndValidation(t *testing.T) {
+	db, seed := setupTestDB(t)
+	repository := NewTodoRepository(db)
+	ctx := context.Background()
+
+	todo := &Todo{UserId: seed.Users["gojo"], Title: "Default"}
+	assert.Nil(t, repository.Create(ctx, todo))
+	saved, err := repository.GetByID(ctx, todo.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, TodoOpen, saved.Status)
+	assert.Equal(t, PriorityMedium, saved.Priority)
+
+	err = repository.Create(ctx, &Todo{Title: "Status salah", Status: "archived"})
+	assert.True(t, errors.Is(err, ErrInvalidStatus))
+	err = repository.Create(ctx, &Todo{Title: "Priority salah", Priority: 9})
+	assert.True(t, errors.Is(err, ErrInvalidPriority))
+}
+
+func TestTodoSetStatus(t *testing.T) {
+	db, seed := setupTestDB(t)
+	repository := NewTodoRepository(db)
+	ctx := context.Background()
+
+	todo := &Todo{UserId: seed.Users["gojo"], Title: "Kerjakan"}
+	assert.Nil(t, repository.Create(ctx, todo))
+
+	done, err := repository.SetStatus(ctx, todo.ID, TodoDone)
+	assert.Nil(t, err)
+	assert.NotNil(t, done.CompletedAt)
+
+	_, err = repository.SetStatus(ctx, todo.ID, TodoInProgress)
+	assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+
+	saved, err := repository.GetByID(ctx, todo.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, TodoDone, saved.Status)
+	assert.NotNil(t, saved.CompletedAt)
+
+	_, err = repository.SetStatus(ctx, 999, TodoDone)
+	assert.Equal(t, ErrTodoNotFound, err)
+}
+
+func TestTodoQueries(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	repository := &todoRepository{db: db, now: func() time.Time { return now }, batchSize: defaultPurgeBatchSize}
+
+	yesterday, lastWeek, tomorrow := now.Add(-24*time.Hour), now.Add(-7*24*time.Hour), now.Add(24*time.Hour)
+	gojo := seed.Users["gojo"]
+	todos := []*Todo{
+		{UserId: gojo, Title: "Laporan", DueAt: &yesterday, Priority: PriorityHigh},
+		{UserId: gojo, Title: "Pajak", DueAt: &lastWeek, Status: TodoInProgress},
+		{UserId: gojo, Title: "Belanja", DueAt: &tomorrow, Priority: PriorityLow},
+		{UserId: gojo, Title: "Selesai", DueAt: &lastWeek, Status: TodoDone},
+		{UserId: seed.Users["nanami"], Title: "Punya Nanami", DueAt: &yesterday},
+	}
+	for _, todo := range todos {
+		assert.Nil(t, repository.Create(ctx, todo))
+	}
+	assert.Nil(t, repository.SetTags(ctx, todos[0].ID, "Kantor", "penting", "kantor"))
+	assert.Nil(t, repository.SetTags(ctx, todos[1].ID, "kantor"))
+	assert.Nil(t, repository.SetTags(ctx, todos[2].ID, "rumah"))
+	assert.Nil(t, repository.SetTags(ctx, todos[4].ID, "kantor"))
+
+	overdue, err := repository.Overdue(ctx, seed.Users["gojo"])
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(overdue))
+	assert.Equal(t, "Pajak", overdue[0].Title)
+	assert.Equal(t, "Laporan", overdue[1].Title)
+	assert.True(t, overdue[1].IsOverdue(now))
+
+	kantor, err := repository.ByTag(ctx, seed.Users["gojo"], " KANTOR ")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(kantor))
+	assert.Equal(t, "Laporan", kantor[0].Title)
+	assert.Equal(t, 2, len(kantor[0].Tags))
+
+	var tags int64
+	assert.Nil(t, db.Model(&Tag{}).Count(&tags).Error)
+	assert.Equal(t, int64(3), tags)
+
+	counts, err := repository.CountByStatus(ctx, seed.Users["gojo"])
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int64{TodoOpen: 2, TodoInProgress: 1, TodoDone: 1}, counts)
+}
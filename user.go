@@ -1,6 +1,10 @@
 package golanggorm
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User => users (contoh penamaan tabel akan dimapping secara otomatis oleh gorm)
 // OrderDetail => order_details (contoh penamaan tabel akan dimapping secara otomatis oleh gorm)
@@ -9,14 +13,20 @@ import "time"
 //ini adalah cara pembuatan model atau entity
 type User struct {
 	ID           int		`gorm:"primary_key;column:id;autoIncrement"`
-	Password     string		`gorm:"column:password"`
+	Password     string		`gorm:"column:password" json:"-"` //tidak ikut json, misal request_hash dan response idempotency
+	Email           *string    `gorm:"column:email;size:255;uniqueIndex:idx_users_email"` //selalu huruf kecil, lihat NormalizeEmail
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"`
 	Name         Name		`gorm:"embedded"` //embedded strcut
 	CreatedAt    time.Time	`gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt    time.Time	`gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Version      Version		`gorm:"column:version;not null;default:1"` //optimistic locking
 	Information  string    	`gorm:"-"`//artinya tidak ada di db 
+	savedPassword string //password terakhir yang dibaca/disimpan ke db, lihat passwordChanged
 	Wallet       Wallet    `gorm:"foreignKey:user_id;references:id"` //one to one (jngan lupa datanya dikasih unique)
-	Addresses    []Address `gorm:"foreignKey:user_id;references:id"` //one to many
+	Addresses    []Address `gorm:"foreignKey:user_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` //one to many
+	Todos        []Todo    `gorm:"foreignKey:user_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` //one to many
 	LikeProducts []Product `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:user_id;references:id;joinReferences:product_id"`
+	Roles        []Role    `gorm:"many2many:user_roles;foreignKey:id;joinForeignKey:user_id;references:id;joinReferences:role_id"` //many to many
 }
 
 //cara mengubah nama table mapping
@@ -24,13 +34,13 @@ func(u *User) TableName() string {
 	return "users"
 }
 
-//hook
-// func (u *User) BeforeCreate(db *gorm.DB) error {
-// 	if u.ID == 0 {
-// 		u.ID = "user-" + time.Now().Format("20060102150405")
-// 	}
-// 	return nil
-// }
+//hook -> password selalu di hash dan email dirapikan sebelum disimpan (create maupun update), plaintext tidak pernah masuk ke db
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	if err := hashPasswordColumn(tx, u); err != nil {
+		return err
+	}
+	return normalizeEmailColumn(tx, u)
+}
 
 //embedded struct -> berguna untuk meng grouping filed-field yang terlalu banyak di struct
 type Name struct {
@@ -41,8 +51,11 @@ type Name struct {
 
 type UserLog struct {
 	ID        int    	`gorm:"primary_key;column:id;autoIncrement"`
-	UserId    string 	`gorm:"column:user_id"`
+	UserId    *int   	`gorm:"column:user_id"` //nil (NULL) jika perubahan tidak dilakukan oleh user (sistem)
 	Action    string 	`gorm:"column:action"`
+	Entity    string 	`gorm:"column:entity;size:100;index:idx_user_logs_entity"` //nama tabel yang diubah (audit)
+	RecordId  string 	`gorm:"column:record_id;size:100;index:idx_user_logs_entity"` //primary key baris yang diubah (audit)
+	Changes   string 	`gorm:"column:changes"` //json diff kolom, lihat UserLog.Diff
 	CreatedAt int64 	`gorm:"column:created_at;autoCreateTime:milli"` //milli adalah timestamp tracking (mengubah waktunya menjadi millisecond)
 	UpdatedAt int64 	`gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"` //milli adalah timestamp tracking (mengubah waktunya menjadi millisecond)
 }
@@ -0,0 +1,204 @@
+package golanggorm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidEmail = errors.New("invalid email address")
+	ErrEmailTaken   = errors.New("email already used by another user")
+	ErrInvalidToken = errors.New("invalid or already used token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// NormalizeEmail -> email dirapikan (trim, huruf kecil) supaya unique index di users.email tidak membedakan huruf besar/kecil
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, email)
+	}
+	return email, nil
+}
+
+// normalizeEmailPointer -> nil dan string kosong berarti user tidak punya email
+func normalizeEmailPointer(email *string) (*string, error) {
+	if email == nil || strings.TrimSpace(*email) == "" {
+		return nil, nil
+	}
+	normalized, err := NormalizeEmail(*email)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// normalizeEmailColumn -> dipanggil dari BeforeSave, sama seperti hashPasswordColumn
+func normalizeEmailColumn(tx *gorm.DB, u *User) error {
+	switch dest := tx.Statement.Dest.(type) {
+	case map[string]interface{}:
+		for _, column := range []string{"email", "Email"} {
+			value, ok := dest[column]
+			if !ok {
+				continue
+			}
+			var email *string
+			switch v := value.(type) {
+			case string:
+				email = &v
+			case *string:
+				email = v
+			case nil:
+			default:
+				return fmt.Errorf("%w: %v", ErrInvalidEmail, value)
+			}
+			normalized, err := normalizeEmailPointer(email)
+			if err != nil {
+				return err
+			}
+			dest[column] = normalized
+		}
+		return nil
+	case *User:
+		if dest != u {
+			normalized, err := normalizeEmailPointer(dest.Email)
+			if err != nil {
+				return err
+			}
+			dest.Email = normalized
+		}
+	case User:
+		if dest.Email != nil {
+			normalized, err := normalizeEmailPointer(dest.Email)
+			if err != nil {
+				return err
+			}
+			tx.Statement.SetColumn("Email", normalized)
+			return nil
+		}
+	}
+
+	normalized, err := normalizeEmailPointer(u.Email)
+	if err != nil {
+		return err
+	}
+	u.Email = normalized
+	return nil
+}
+
+// UserEmailVerification -> token verifikasi email, yang disimpan hanya hash dari token
+type UserEmailVerification struct {
+	ID        int64      `gorm:"primaryKey;column:id;autoIncrement"`
+	UserId    int        `gorm:"column:user_id;index"`
+	Email     string     `gorm:"column:email;size:255"`
+	TokenHash string     `gorm:"column:token_hash;size:64;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v *UserEmailVerification) TableName() string {
+	return "user_email_verifications"
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// EmailVerificationService -> membuat dan memeriksa token verifikasi email
+type EmailVerificationService struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewEmailVerificationService(db *gorm.DB, ttl time.Duration) *EmailVerificationService {
+	return &EmailVerificationService{db: db, ttl: ttl, now: time.Now}
+}
+
+// Issue -> token baru untuk email user saat ini, token dikirim ke user dan tidak disimpan
+func (s *EmailVerificationService) Issue(ctx context.Context, userID int) (string, error) {
+	var user User
+	err := s.db.WithContext(ctx).Take(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if user.Email == nil {
+		return "", fmt.Errorf("%w: user %d has no email", ErrInvalidEmail, userID)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	verification := UserEmailVerification{
+		UserId:    user.ID,
+		Email:     *user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: s.now().Add(s.ttl),
+	}
+	if err := s.db.WithContext(ctx).Create(&verification).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Verify -> menandai email user sudah terverifikasi, token hanya bisa dipakai sekali
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (*User, error) {
+	var user User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var verification UserEmailVerification
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Take(&verification, "token_hash = ? AND used_at IS NULL", hashToken(token)).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+		now := s.now()
+		if !now.Before(verification.ExpiresAt) {
+			return ErrTokenExpired
+		}
+
+		if err := tx.Take(&user, "id = ?", verification.UserId).Error; err != nil {
+			return err
+		}
+		//email sudah diganti setelah token dibuat
+		if user.Email == nil || *user.Email != verification.Email {
+			return ErrInvalidToken
+		}
+
+		if err := tx.Model(&verification).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,120 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func email(value string) *string {
+	return &value
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	normalized, err := NormalizeEmail("  Gojo.Satoru@Example.COM ")
+	assert.Nil(t, err)
+	assert.Equal(t, "gojo.satoru@example.com", normalized)
+
+	for _, invalid := range []string{"gojo", "gojo@", "Gojo <gojo@example.com>", "gojo@example.com, toji@example.com"} {
+		_, err := NormalizeEmail(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidEmail), invalid)
+	}
+}
+
+func TestEmailHook(t *testing.T) {
+	db, seed := setupTestDB(t)
+
+	//fixture ditulis "Nanami@Example.com"
+	var user User
+	assert.Nil(t, db.Take(&user, "id = ?", seed.Users["nanami"]).Error)
+	assert.Equal(t, "nanami@example.com", *user.Email)
+
+	assert.Nil(t, db.Model(&user).Update("email", " NANAMI.kento@example.com").Error)
+	assert.Nil(t, db.Take(&user, "id = ?", seed.Users["nanami"]).Error)
+	assert.Equal(t, "nanami.kento@example.com", *user.Email)
+
+	err := db.Create(&User{Password: "rahasia", Email: email("bukan email")}).Error
+	assert.True(t, errors.Is(err, ErrInvalidEmail))
+
+	//unique index tetap berlaku walau huruf besar/kecil berbeda
+	err = db.Create(&User{Password: "rahasia", Email: email("GOJO@example.com")}).Error
+	assert.NotNil(t, err)
+
+	//string kosong disimpan sebagai NULL, jadi boleh lebih dari satu
+	assert.Nil(t, db.Create(&User{Password: "rahasia", Email: email("")}).Error)
+	assert.Nil(t, db.Create(&User{Password: "rahasia", Email: email("")}).Error)
+}
+
+func TestGetByEmail(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			user := User{Password: "rahasia", Name: Name{FirstName: "Gojo"}, Email: email("Gojo@Example.com")}
+			assert.Nil(t, repo.Create(ctx, &user))
+
+			found, err := repo.GetByEmail(ctx, "GOJO@example.com")
+			assert.Nil(t, err)
+			assert.Equal(t, user.ID, found.ID)
+
+			_, err = repo.GetByEmail(ctx, "toji@example.com")
+			assert.True(t, errors.Is(err, ErrUserNotFound))
+
+			other := User{Password: "rahasia", Name: Name{FirstName: "Toji"}, Email: email("gojo@EXAMPLE.com")}
+			assert.True(t, errors.Is(repo.Create(ctx, &other), ErrEmailTaken))
+
+			found, err = NewAuthService(repo).Authenticate(ctx, " gojo@example.com", "rahasia")
+			assert.Nil(t, err)
+			assert.Equal(t, user.ID, found.ID)
+		})
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	service := NewEmailVerificationService(db, time.Hour)
+
+	token, err := service.Issue(ctx, seed.Users["gojo"])
+	assert.Nil(t, err)
+
+	//token tidak disimpan apa adanya
+	var count int64
+	assert.Nil(t, db.Model(&UserEmailVerification{}).Where("token_hash = ?", token).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	user, err := service.Verify(ctx, token)
+	assert.Nil(t, err)
+	assert.Equal(t, seed.Users["gojo"], user.ID)
+	assert.NotNil(t, user.EmailVerifiedAt)
+
+	_, err = service.Verify(ctx, token)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+
+	//user tanpa email
+	_, err = service.Issue(ctx, seed.Users["toji"])
+	assert.True(t, errors.Is(err, ErrInvalidEmail))
+}
+
+func TestEmailVerificationExpired(t *testing.T) {
+	db, seed := setupTestDB(t)
+	ctx := context.Background()
+	service := NewEmailVerificationService(db, time.Hour)
+
+	token, err := service.Issue(ctx, seed.Users["nanami"])
+	assert.Nil(t, err)
+
+	service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = service.Verify(ctx, token)
+	assert.True(t, errors.Is(err, ErrTokenExpired))
+
+	//email diganti setelah token dibuat, token lama tidak berlaku
+	service.now = time.Now
+	token, err = service.Issue(ctx, seed.Users["nanami"])
+	assert.Nil(t, err)
+	assert.Nil(t, db.Model(&User{}).Where("id = ?", seed.Users["nanami"]).Update("email", "baru@example.com").Error)
+	_, err = service.Verify(ctx, token)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+}
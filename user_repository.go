@@ -0,0 +1,432 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// relasi User yang bisa dipakai di WithRelations
+const (
+	RelationWallet       = "Wallet"
+	RelationAddresses    = "Addresses"
+	RelationLikeProducts = "LikeProducts"
+)
+
+// UserFilter -> kondisi untuk List, field yang kosong tidak dipakai
+type UserFilter struct {
+	Name string // sebagian dari first/middle/last name, tidak case sensitive
+	IDs  []int
+}
+
+// Page -> pagination, Number dimulai dari 1
+type Page struct {
+	Number int
+	Size   int
+}
+
+const defaultPageSize = 20
+
+func (p Page) limit() int {
+	if p.Size <= 0 {
+		return defaultPageSize
+	}
+	return p.Size
+}
+
+func (p Page) offset() int {
+	if p.Number <= 1 {
+		return 0
+	}
+	return (p.Number - 1) * p.limit()
+}
+
+// UserRepository -> semua akses data User lewat interface ini, bukan query string langsung
+type UserRepository interface {
+	GetByID(ctx context.Context, id int) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	FindByName(ctx context.Context, name string) ([]User, error)
+	List(ctx context.Context, filter UserFilter, page Page) ([]User, int64, error)
+	Create(ctx context.Context, user *User) error
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id int) error
+	// WithRelations -> repository baru yang ikut memuat relasi (Wallet, Addresses, LikeProducts)
+	WithRelations(relations ...string) UserRepository
+}
+
+type userRepository struct {
+	db        *gorm.DB
+	relations []string
+}
+
+// NewUserRepository -> UserRepository yang memakai gorm
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) WithRelations(relations ...string) UserRepository {
+	return &userRepository{db: r.db, relations: append(append([]string{}, r.relations...), relations...)}
+}
+
+func (r *userRepository) query(ctx context.Context) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	for _, relation := range r.relations {
+		db = db.Preload(relation)
+	}
+	return db
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int) (*User, error) {
+	var user User
+	err := r.query(ctx).Take(&user, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	normalized, err := NormalizeEmail(email)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	var user User
+	err = r.query(ctx).Take(&user, "email = ?", normalized).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByName(ctx context.Context, name string) ([]User, error) {
+	var users []User
+	err := r.query(ctx).Scopes(userNameLike(name)).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) List(ctx context.Context, filter UserFilter, page Page) ([]User, int64, error) {
+	db := r.query(ctx).Model(&User{})
+	if filter.Name != "" {
+		db = db.Scopes(userNameLike(filter.Name))
+	}
+	if len(filter.IDs) > 0 {
+		db = db.Where("id IN ?", filter.IDs)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []User
+	err := db.Order("id").Limit(page.limit()).Offset(page.offset()).Find(&users).Error
+	return users, total, err
+}
+
+// Create -> jika ctx punya WithIdempotencyKey, retry dengan key yang sama mengembalikan user yang sudah dibuat
+func (r *userRepository) Create(ctx context.Context, user *User) error {
+	request := struct {
+		Op   string
+		User User
+	}{"create_user", *user}
+	created := false
+	err := idempotentTransaction(ctx, r.db, request, user, func(tx *gorm.DB) error {
+		created = true
+		return tx.Create(user).Error
+	})
+	if err == nil && !created {
+		//retry: response yang tersimpan tidak berisi password, hash diambil dari db
+		var stored User
+		if err := r.db.WithContext(ctx).Select("password").Take(&stored, "id = ?", user.ID).Error; err != nil {
+			return err
+		}
+		user.Password = stored.Password
+	}
+	return r.duplicateEmail(ctx, err, user)
+}
+
+func (r *userRepository) Update(ctx context.Context, user *User) error {
+	//Select("*") supaya field yang kosong juga ikut diupdate, relasi tidak ikut disimpan
+	result := r.db.WithContext(ctx).Model(user).Select("*").Omit("created_at", clause.Associations).Updates(user)
+	if result.Error != nil {
+		return r.duplicateEmail(ctx, result.Error, user)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&User{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// duplicateEmail -> duplicate key karena email dipakai user lain menjadi ErrEmailTaken
+func (r *userRepository) duplicateEmail(ctx context.Context, err error, user *User) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || user.Email == nil {
+		return err
+	}
+	var count int64
+	if r.db.WithContext(ctx).Model(&User{}).Where("email = ? AND id <> ?", *user.Email, user.ID).Count(&count).Error == nil && count > 0 {
+		return fmt.Errorf("%w: %s", ErrEmailTaken, *user.Email)
+	}
+	return err
+}
+
+func userNameLike(name string) func(db *gorm.DB) *gorm.DB {
+	pattern := "%" + strings.ToLower(name) + "%"
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("LOWER(first_name) LIKE ? OR LOWER(middle_name) LIKE ? OR LOWER(last_name) LIKE ?", pattern, pattern, pattern)
+	}
+}
+
+// memoryUserRepository -> UserRepository palsu di memory, untuk test yang tidak butuh database
+type memoryUserRepository struct {
+	store     *memoryUserStore
+	relations []string
+}
+
+type memoryUserStore struct {
+	mu          sync.Mutex
+	nextID      int
+	users       map[int]User
+	idempotency map[string]memoryIdempotentCreate
+}
+
+type memoryIdempotentCreate struct {
+	hash string
+	user User
+}
+
+// NewMemoryUserRepository -> UserRepository yang menyimpan data di memory
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{store: &memoryUserStore{users: map[int]User{}, idempotency: map[string]memoryIdempotentCreate{}}}
+}
+
+func (r *memoryUserRepository) WithRelations(relations ...string) UserRepository {
+	return &memoryUserRepository{store: r.store, relations: append(append([]string{}, r.relations...), relations...)}
+}
+
+// view -> salinan user, relasi yang tidak diminta dikosongkan seperti tanpa Preload
+func (r *memoryUserRepository) view(user User) User {
+	loaded := map[string]bool{}
+	for _, relation := range r.relations {
+		loaded[relation] = true
+	}
+	if !loaded[RelationWallet] {
+		user.Wallet = Wallet{}
+	}
+	if !loaded[RelationAddresses] {
+		user.Addresses = nil
+	} else {
+		user.Addresses = append([]Address(nil), user.Addresses...)
+	}
+	if !loaded[RelationLikeProducts] {
+		user.LikeProducts = nil
+	} else {
+		user.LikeProducts = append([]Product(nil), user.LikeProducts...)
+	}
+	user.Information = ""
+	return user
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id int) (*User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	user = r.view(user)
+	return &user, nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	normalized, err := NormalizeEmail(email)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if user.Email != nil && *user.Email == normalized {
+			user = r.view(user)
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// prepare -> hook BeforeSave versi memory: hash password, rapikan email dan cek email unik
+func (s *memoryUserStore) prepare(user *User) error {
+	password := user.Password
+	if user.passwordChanged() {
+		var err error
+		if password, err = HashPassword(user.Password); err != nil {
+			return err
+		}
+	}
+	email, err := normalizeEmailPointer(user.Email)
+	if err != nil {
+		return err
+	}
+	if email != nil {
+		for _, other := range s.users {
+			if other.ID != user.ID && other.Email != nil && *other.Email == *email {
+				return fmt.Errorf("%w: %s", ErrEmailTaken, *email)
+			}
+		}
+	}
+	user.Password, user.Email = password, email
+	user.savedPassword = password //sama seperti AfterSave di gorm
+	return nil
+}
+
+func (r *memoryUserRepository) FindByName(ctx context.Context, name string) ([]User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.filter(UserFilter{Name: name}), nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, filter UserFilter, page Page) ([]User, int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	matched := r.filter(filter)
+	total := int64(len(matched))
+	start := page.offset()
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + page.limit()
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], total, nil
+}
+
+// filter -> user yang cocok dengan filter urut id, r.store.mu harus sudah di lock
+func (r *memoryUserRepository) filter(filter UserFilter) []User {
+	ids := map[int]bool{}
+	for _, id := range filter.IDs {
+		ids[id] = true
+	}
+	name := strings.ToLower(filter.Name)
+
+	var matched []User
+	for _, user := range r.store.users {
+		if len(ids) > 0 && !ids[user.ID] {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(user.Name.FirstName), name) &&
+			!strings.Contains(strings.ToLower(user.Name.MiddleName), name) &&
+			!strings.Contains(strings.ToLower(user.Name.LastName), name) {
+			continue
+		}
+		matched = append(matched, r.view(user))
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return matched
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, idempotent := IdempotencyKeyFrom(ctx)
+	var hash string
+	if idempotent {
+		var err error
+		hash, err = requestHash(struct {
+			Op   string
+			User User
+		}{"create_user", *user})
+		if err != nil {
+			return err
+		}
+		if previous, ok := r.store.idempotency[key]; ok {
+			if previous.hash != hash {
+				return fmt.Errorf("%w: %s", ErrIdempotencyConflict, key)
+			}
+			*user = previous.user
+			return nil
+		}
+	}
+
+	if err := r.store.prepare(user); err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		r.store.nextID++
+		user.ID = r.store.nextID
+	} else if _, exists := r.store.users[user.ID]; exists {
+		return gorm.ErrDuplicatedKey
+	} else if user.ID > r.store.nextID {
+		r.store.nextID = user.ID
+	}
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	r.store.users[user.ID] = *user
+	if idempotent {
+		r.store.idempotency[key] = memoryIdempotentCreate{hash: hash, user: *user}
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.users[user.ID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if user.Version != 0 && user.Version != existing.Version {
+		return fmt.Errorf("%w: users version %d", ErrStaleObject, user.Version)
+	}
+	if err := r.store.prepare(user); err != nil {
+		return err
+	}
+	user.Version = existing.Version + 1
+	updated := *user
+	updated.CreatedAt = existing.CreatedAt
+	//relasi tidak ikut disimpan saat update, sama seperti versi gorm
+	updated.Wallet, updated.Addresses, updated.LikeProducts = existing.Wallet, existing.Addresses, existing.LikeProducts
+	r.store.users[user.ID] = updated
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.store.users, id)
+	return nil
+}
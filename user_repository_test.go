@@ -0,0 +1,137 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// userRepositories -> implementasi gorm dan memory harus berperilaku sama
+func userRepositories(t *testing.T) map[string]UserRepository {
+	db, _ := setupTestDB(t)
+	//hapus data fixture supaya kedua repository mulai dari kosong
+	assert.Nil(t, db.Exec("DELETE FROM user_like_product").Error)
+	assert.Nil(t, db.Exec("DELETE FROM addresses").Error)
+	assert.Nil(t, db.Exec("DELETE FROM wallets").Error)
+	assert.Nil(t, db.Exec("DELETE FROM users").Error)
+
+	return map[string]UserRepository{
+		"gorm":   NewUserRepository(db),
+		"memory": NewMemoryUserRepository(),
+	}
+}
+
+func TestUserRepositoryCRUD(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			user := User{Password: "rahasia", Name: Name{FirstName: "Gojo", LastName: "Satoru"}}
+			assert.Nil(t, repo.Create(ctx, &user))
+			assert.NotEqual(t, 0, user.ID)
+
+			found, err := repo.GetByID(ctx, user.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, "Gojo", found.Name.FirstName)
+
+			found.Name.LastName = ""
+			found.Password = "baru"
+			assert.Nil(t, repo.Update(ctx, found))
+
+			found, err = repo.GetByID(ctx, user.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, "", found.Name.LastName)
+			assert.True(t, found.CheckPassword("baru"))
+
+			assert.Nil(t, repo.Delete(ctx, user.ID))
+			_, err = repo.GetByID(ctx, user.ID)
+			assert.True(t, errors.Is(err, ErrUserNotFound))
+			assert.True(t, errors.Is(repo.Delete(ctx, user.ID), ErrUserNotFound))
+			assert.True(t, errors.Is(repo.Update(ctx, &User{ID: user.ID}), ErrUserNotFound))
+		})
+	}
+}
+
+func TestUserRepositoryQuery(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			var ids []int
+			for _, first := range []string{"Gojo", "Nanami", "Kento", "Toji", "Megumi"} {
+				user := User{Password: "rahasia", Name: Name{FirstName: first, LastName: "Aji"}}
+				assert.Nil(t, repo.Create(ctx, &user))
+				ids = append(ids, user.ID)
+			}
+
+			users, err := repo.FindByName(ctx, "nAnAmI")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(users))
+			assert.Equal(t, "Nanami", users[0].Name.FirstName)
+
+			users, total, err := repo.List(ctx, UserFilter{Name: "aji"}, Page{Number: 2, Size: 2})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(5), total)
+			assert.Equal(t, 2, len(users))
+			assert.Equal(t, ids[2], users[0].ID)
+
+			users, total, err = repo.List(ctx, UserFilter{IDs: ids[:2]}, Page{})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), total)
+			assert.Equal(t, ids[:2], []int{users[0].ID, users[1].ID})
+		})
+	}
+}
+
+func TestUserRepositoryWithRelations(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range userRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			user := User{
+				Password:  "rahasia",
+				Name:      Name{FirstName: "User A"},
+				Wallet:    Wallet{Balance: 2000},
+				Addresses: []Address{{Address: "Jalan A"}, {Address: "Jalan B"}},
+			}
+			assert.Nil(t, repo.Create(ctx, &user))
+
+			plain, err := repo.GetByID(ctx, user.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), plain.Wallet.Balance)
+			assert.Equal(t, 0, len(plain.Addresses))
+
+			loaded, err := repo.WithRelations(RelationWallet, RelationAddresses).GetByID(ctx, user.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2000), loaded.Wallet.Balance)
+			assert.Equal(t, 2, len(loaded.Addresses))
+		})
+	}
+}
+
+func TestMemoryUserRepositoryConcurrent(t *testing.T) {
+	repo := NewMemoryUserRepository()
+	ctx := context.Background()
+
+	//dijalankan dengan go test -race, FindByName tidak boleh membaca store tanpa lock
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			user := User{Password: "rahasia", Name: Name{FirstName: fmt.Sprintf("Gojo %d", i)}}
+			assert.Nil(t, repo.Create(ctx, &user))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := repo.FindByName(ctx, "gojo")
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	users, err := repo.FindByName(ctx, "gojo")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(users))
+}
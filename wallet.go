@@ -1,16 +1,37 @@
 package golanggorm
 
-import "time"
+import (
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Wallet struct {
 	ID        int    	`gorm:"primary_key;column:id"`
-	UserId    int    	`gorm:"column:user_id"`
+	UserId    int    	`gorm:"column:user_id;uniqueIndex:idx_wallets_user_id"`
 	Balance   int64     `gorm:"column:balance"`
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Version   Version   `gorm:"column:version;not null;default:1"` //optimistic locking
 	User      *User     `gorm:"foreignKey:user_id;references:id"`//relasi belongs to (one to one)
 }
 
 func (w *Wallet) TableName() string {
 	return "wallets"
-}
\ No newline at end of file
+}
+// AfterCreate -> saldo awal wallet dicatat di ledger, lawannya SystemWalletID
+func (w *Wallet) AfterCreate(tx *gorm.DB) error {
+	if w.Balance == 0 {
+		return nil
+	}
+	direction, counter := LedgerCredit, LedgerDebit
+	amount := w.Balance
+	if amount < 0 {
+		direction, counter, amount = LedgerDebit, LedgerCredit, -amount
+	}
+	return recordLedger(tx.Session(&gorm.Session{NewDB: true}), "opening-"+strconv.Itoa(w.ID),
+		LedgerEntry{WalletId: w.ID, Amount: amount, Direction: direction},
+		LedgerEntry{WalletId: SystemWalletID, Amount: amount, Direction: counter},
+	)
+}
@@ -0,0 +1,85 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrWalletNotFound    = errors.New("wallet not found")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+	ErrSameWallet        = errors.New("cannot transfer to the same wallet")
+)
+
+// WalletService -> operasi saldo wallet yang harus atomic
+type WalletService struct {
+	db *gorm.DB
+}
+
+func NewWalletService(db *gorm.DB) *WalletService {
+	return &WalletService{db: db}
+}
+
+// Transfer -> memindahkan amount dari wallet fromUserID ke wallet toUserID dalam satu transaction.
+// Jika ctx punya WithIdempotencyKey, retry dengan key yang sama tidak memindahkan uang lagi.
+func (s *WalletService) Transfer(ctx context.Context, fromUserID, toUserID int, amount int64) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if fromUserID == toUserID {
+		return ErrSameWallet
+	}
+
+	request := struct {
+		Op     string
+		From   int
+		To     int
+		Amount int64
+	}{"transfer", fromUserID, toUserID, amount}
+	return idempotentTransaction(ctx, s.db, request, nil, func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, fromUserID, toUserID)
+		if err != nil {
+			return err
+		}
+		from, to := wallets[fromUserID], wallets[toUserID]
+		if from.Balance < amount {
+			return fmt.Errorf("%w: balance %d, amount %d", ErrInsufficientFunds, from.Balance, amount)
+		}
+
+		reference, err := newReference("transfer")
+		if err != nil {
+			return err
+		}
+		return postLedger(tx, reference,
+			LedgerEntry{WalletId: from.ID, Amount: amount, Direction: LedgerDebit},
+			LedgerEntry{WalletId: to.ID, Amount: amount, Direction: LedgerCredit},
+		)
+	})
+}
+
+// lockWallets -> SELECT ... FOR UPDATE wallet milik userIDs, selalu urut user_id dari kecil
+// supaya dua transfer yang berlawanan arah tidak saling menunggu (deadlock)
+func lockWallets(tx *gorm.DB, userIDs ...int) (map[int]*Wallet, error) {
+	ordered := append([]int{}, userIDs...)
+	sort.Ints(ordered)
+
+	wallets := make(map[int]*Wallet, len(ordered))
+	for _, userID := range ordered {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&wallet, "user_id = ?", userID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user %d", ErrWalletNotFound, userID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		wallets[userID] = &wallet
+	}
+	return wallets, nil
+}
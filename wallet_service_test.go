@@ -0,0 +1,91 @@
+package golanggorm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func walletBalance(t *testing.T, service *WalletService, userID int) int64 {
+	var wallet Wallet
+	assert.Nil(t, service.db.Take(&wallet, "user_id = ?", userID).Error)
+	return wallet.Balance
+}
+
+func TestTransfer(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	err := service.Transfer(ctx, seed.Users["nanami"], seed.Users["gojo"], 400000)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(600000), walletBalance(t, service, seed.Users["nanami"]))
+	assert.Equal(t, int64(400100), walletBalance(t, service, seed.Users["gojo"]))
+}
+
+func TestTransferErrors(t *testing.T) {
+	db, seed := setupTestDB(t)
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	err := service.Transfer(ctx, seed.Users["gojo"], seed.Users["nanami"], 101)
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+
+	err = service.Transfer(ctx, seed.Users["gojo"], seed.Users["nanami"], 0)
+	assert.True(t, errors.Is(err, ErrInvalidAmount))
+
+	err = service.Transfer(ctx, seed.Users["gojo"], seed.Users["gojo"], 10)
+	assert.True(t, errors.Is(err, ErrSameWallet))
+
+	//user_b tidak punya wallet
+	err = service.Transfer(ctx, seed.Users["gojo"], seed.Users["user_b"], 10)
+	assert.True(t, errors.Is(err, ErrWalletNotFound))
+
+	//semua transfer gagal, saldo tidak berubah
+	assert.Equal(t, int64(100), walletBalance(t, service, seed.Users["gojo"]))
+	assert.Equal(t, int64(1000000), walletBalance(t, service, seed.Users["nanami"]))
+}
+
+// TestTransferConcurrent -> transfer dari banyak koneksi sekaligus, dengan TEST_DB_DSN (mysql/postgres) urutan lock
+// di lockWallets yang mencegah saldo minus dan deadlock transfer yang berlawanan arah
+func TestTransferConcurrent(t *testing.T) {
+	db, seed := setupConcurrentTestDB(t)
+	service := NewWalletService(db)
+	ctx := context.Background()
+
+	users := []int{seed.Users["gojo"], seed.Users["nanami"], seed.Users["laksa"], seed.Users["kento"], seed.Users["toji"], seed.Users["user_a"]}
+	var before int64
+	assert.Nil(t, db.Model(&Wallet{}).Select("SUM(balance)").Scan(&before).Error)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from, to := users[i%len(users)], users[(i*7+1)%len(users)]
+			if from == to {
+				to = users[(i+1)%len(users)]
+			}
+			err := service.Transfer(ctx, from, to, int64(i+1)*1500)
+			if err != nil && !errors.Is(err, ErrInsufficientFunds) {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	assert.Greater(t, sqlDB.Stats().OpenConnections, 1) //transfer benar-benar berjalan di beberapa koneksi
+
+	var after int64
+	assert.Nil(t, db.Model(&Wallet{}).Select("SUM(balance)").Scan(&after).Error)
+	assert.Equal(t, before, after)
+
+	var negative int64
+	assert.Nil(t, db.Model(&Wallet{}).Where("balance < 0").Count(&negative).Error)
+	assert.Equal(t, int64(0), negative)
+}